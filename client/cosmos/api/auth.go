@@ -3,6 +3,8 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+
+	sdkmath "cosmossdk.io/math"
 )
 
 type Bech32PrefixResponse struct {
@@ -18,7 +20,7 @@ type AcctResponse struct {
 				PubKey        string `json:"public_key,omitempty"`
 				AccountNumber string `json:"account_number,omitempty"`
 				Sequence      string `json:"sequence,omitempty"`
-			}
+			} `json:"base_account"`
 			OriginalVesting []struct {
 				Denom  string `json:"denom"`
 				Amount string `json:"amount"`
@@ -44,19 +46,35 @@ type AcctResponse struct {
 	} `json:"account"`
 }
 
-func (a *AcctResponse) GetBalances() map[int]map[string]string {
-	balances := make(map[int]map[string]string)
-	balances[OriginalVesting] = make(map[string]string)
-	balances[DelegatedVesting] = make(map[string]string)
+func (a *AcctResponse) GetBalances() (map[int]map[string]sdkmath.Int, error) {
+	balances := make(map[int]map[string]sdkmath.Int)
+	balances[OriginalVesting] = make(map[string]sdkmath.Int)
+	balances[DelegatedVesting] = make(map[string]sdkmath.Int)
+	balances[DelegatedFree] = make(map[string]sdkmath.Int)
 
 	for _, balance := range a.Account.BaseVestingAccount.OriginalVesting {
-		balances[OriginalVesting][balance.Denom] = balance.Amount
+		if err := addAmount(balances[OriginalVesting], balance.Denom, balance.Amount); err != nil {
+			return nil, err
+		}
 	}
 
 	for _, balance := range a.Account.BaseVestingAccount.DelegatedVesting {
-		balances[DelegatedVesting][balance.Denom] = balance.Amount
+		if err := addAmount(balances[DelegatedVesting], balance.Denom, balance.Amount); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, balance := range a.Account.BaseVestingAccount.DelegatedFree {
+		if err := addAmount(balances[DelegatedFree], balance.Denom, balance.Amount); err != nil {
+			return nil, err
+		}
 	}
-	return balances
+	return balances, nil
+}
+
+// IsVesting returns true for vesting accounts, whatever their type
+func (a *AcctResponse) IsVesting() bool {
+	return len(a.Account.BaseVestingAccount.OriginalVesting) > 0
 }
 
 func (p *Bech32PrefixResponse) GetPrefix(endpointURL string, client *http.Client) error {
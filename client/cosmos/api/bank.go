@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"strings"
+
+	sdkmath "cosmossdk.io/math"
 )
 
 type BankResponse struct {
@@ -11,10 +13,12 @@ type BankResponse struct {
 		Denom  string `json:"denom"`
 		Amount string `json:"amount"`
 	} `json:"balances"`
-	Pagination struct {
-		NextKey interface{} `json:"next_key"`
-		Total   string      `json:"total"`
-	} `json:"pagination"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// SpendableResponse holds the balances of an account that are not locked by vesting
+type SpendableResponse struct {
+	BankResponse
 }
 
 type DenomMetadataResponse struct {
@@ -30,27 +34,52 @@ type DenomMetadataResponse struct {
 	} `json:"metadata"`
 }
 
-func (b *BankResponse) GetBalances() map[int]map[string]string {
-	balances := make(map[int]map[string]string)
-	balances[Bank] = map[string]string{}
+func (b *BankResponse) GetBalances() (map[int]map[string]sdkmath.Int, error) {
+	balances := make(map[int]map[string]sdkmath.Int)
+	balances[Bank] = make(map[string]sdkmath.Int)
 
 	for _, balance := range b.Balances {
-		balances[Bank][balance.Denom] = balance.Amount
+		if err := addAmount(balances[Bank], balance.Denom, balance.Amount); err != nil {
+			return nil, err
+		}
 	}
-	return balances
+	return balances, nil
 }
 
-func (b *BankResponse) QueryBankBalances(address string, endpoint string, client *http.Client) error {
-	var body []byte
-
-	url := endpoint + "/cosmos/bank/v1beta1/balances/" + address
-	body, err := HttpGet(url, client)
+func (s *SpendableResponse) GetBalances() (map[int]map[string]sdkmath.Int, error) {
+	balances := make(map[int]map[string]sdkmath.Int)
+	balances[Spendable] = make(map[string]sdkmath.Int)
 
-	err = json.Unmarshal(body, b)
-	if err != nil {
-		return err
+	for _, balance := range s.Balances {
+		if err := addAmount(balances[Spendable], balance.Denom, balance.Amount); err != nil {
+			return nil, err
+		}
 	}
-	return nil
+	return balances, nil
+}
+
+func (s *SpendableResponse) QuerySpendableBalances(address string, endpoint string, client *http.Client) error {
+	url := endpoint + "/cosmos/bank/v1beta1/spendable_balances/" + address
+	return GetAllPages(url, client, func(body []byte) (Pagination, error) {
+		var page BankResponse
+		if err := json.Unmarshal(body, &page); err != nil {
+			return Pagination{}, err
+		}
+		s.Balances = append(s.Balances, page.Balances...)
+		return page.Pagination, nil
+	})
+}
+
+func (b *BankResponse) QueryBankBalances(address string, endpoint string, client *http.Client) error {
+	url := endpoint + "/cosmos/bank/v1beta1/balances/" + address
+	return GetAllPages(url, client, func(body []byte) (Pagination, error) {
+		var page BankResponse
+		if err := json.Unmarshal(body, &page); err != nil {
+			return Pagination{}, err
+		}
+		b.Balances = append(b.Balances, page.Balances...)
+		return page.Pagination, nil
+	})
 }
 
 func (d *DenomMetadataResponse) QueryMetadataFromBank(denom string, endpoint string, client *http.Client) error {
@@ -2,8 +2,11 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 )
 
 type AssetList struct {
@@ -22,6 +25,12 @@ type AssetList struct {
 
 type ChainData struct {
 	Bech32Prefix string `json:"bech32_prefix"`
+	Apis         struct {
+		Rest []struct {
+			Address  string `json:"address"`
+			Provider string `json:"provider"`
+		} `json:"rest"`
+	} `json:"apis"`
 }
 
 // SearchForAsset search for the symbol for a particular denom in the assets list
@@ -73,3 +82,83 @@ func (c *ChainData) QueryChainData(chain string, client *http.Client) error {
 	}
 	return nil
 }
+
+type registryChains struct {
+	Chains []struct {
+		Name    string `json:"name"`
+		ChainId string `json:"chain_id"`
+	} `json:"chains"`
+}
+
+// AssetDirectory finds the asset list of a chain by its chain id, e.g. to describe the origin of an IBC
+// denom. Asset lists are fetched from the chain registry once and shared by all chains
+type AssetDirectory struct {
+	client *http.Client
+	mu     sync.Mutex
+	names  map[string]string
+	lists  map[string]*AssetList
+	// registryErr is set when the chain registry could not be queried
+	registryErr error
+}
+
+func NewAssetDirectory(client *http.Client) *AssetDirectory {
+	return &AssetDirectory{
+		client: client,
+		lists:  make(map[string]*AssetList),
+	}
+}
+
+// Add registers the asset list of a chain that was already fetched
+func (d *AssetDirectory) Add(chainId string, list *AssetList) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lists[chainId] = list
+}
+
+// AssetList returns the asset list of the chain with the given chain id
+func (d *AssetDirectory) AssetList(chainId string) (*AssetList, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if list, ok := d.lists[chainId]; ok {
+		if list == nil {
+			return nil, errors.New(fmt.Sprintf("no asset list for %s", chainId))
+		}
+		return list, nil
+	}
+
+	if d.names == nil {
+		// the registry is only queried once, also when it fails
+		d.names = make(map[string]string)
+		var registry registryChains
+		body, err := HttpGet("https://chains.cosmos.directory/", d.client)
+		if err == nil {
+			err = json.Unmarshal(body, &registry)
+		}
+		if err != nil {
+			d.registryErr = errors.New(fmt.Sprintf("query chain registry: %s", err))
+		}
+
+		for _, chain := range registry.Chains {
+			d.names[chain.ChainId] = chain.Name
+		}
+	}
+	if d.registryErr != nil {
+		return nil, d.registryErr
+	}
+
+	name, ok := d.names[chainId]
+	if !ok {
+		// remember unknown chains so the registry is not searched again
+		d.lists[chainId] = nil
+		return nil, errors.New(fmt.Sprintf("chain %s not found in the chain registry", chainId))
+	}
+
+	list := &AssetList{}
+	if err := list.QueryAssetList(name, d.client); err != nil {
+		d.lists[chainId] = nil
+		return nil, err
+	}
+	d.lists[chainId] = list
+	return list, nil
+}
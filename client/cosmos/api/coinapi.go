@@ -15,77 +15,37 @@ type AssetPair struct {
 	AssetIdQuote string  `json:"asset_id_quote"`
 }
 
-func (c *AssetPair) GetCoinGekoQuote() error {
-
-	var url string
-	key := os.Getenv("COINAPI_KEY")
-
-	if key == "" {
-		return errors.New("COINAPI_KEY environment variable is not set")
-	}
-
-	if c.AssetIdQuote == "USD" {
-		url = "https://api.coingecko.com/api/v3/simple/price?ids=" + c.AssetIdBase + "&vs_currencies=usd"
-	} else {
-		url = "https://api.coingecko.com/api/v3/simple/price?ids=" + c.AssetIdBase + "&vs_currencies=cad"
-	}
-
-	method := "GET"
-	client := &http.Client{}
-	req, err := http.NewRequest(method, url, nil)
-
-	if err != nil {
-		return err
-	}
-
-	req.Header.Add("Accept", "text/plain")
-
-	res, err := client.Do(req)
-	if err != nil {
-		return err
-	}
+// CoinApiProvider fetches exchange rates from coinapi.io, it requires the COINAPI_KEY environment variable
+type CoinApiProvider struct {
+	Client *http.Client
+}
 
-	defer func(Body io.ReadCloser) {
-		err = Body.Close()
-		if err != nil {
-			return
-		}
-	}(res.Body)
+func (p *CoinApiProvider) Name() string {
+	return "coinapi"
+}
 
-	if res.StatusCode != 200 {
-		return errors.New(fmt.Sprintf("coingeko request failed with status code: %s", res.Status))
+func (p *CoinApiProvider) GetQuote(symbol string, quote string) (float64, error) {
+	pair := AssetPair{
+		AssetIdBase:  symbol,
+		AssetIdQuote: quote,
 	}
 
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return err
-	}
-
-	err = json.Unmarshal(body, c)
-	if err != nil {
-		return err
+	if err := pair.GetCoinApiQuote(p.Client); err != nil {
+		return 0, err
 	}
-
-	return nil
+	return pair.Rate, nil
 }
 
-func (c *AssetPair) GetCoinApiQuote() error {
-
-	var url string
+func (c *AssetPair) GetCoinApiQuote(client *http.Client) error {
 	key := os.Getenv("COINAPI_KEY")
 
 	if key == "" {
 		return errors.New("COINAPI_KEY environment variable is not set")
 	}
 
-	if c.AssetIdQuote == "USD" {
-		url = "https://rest.coinapi.io/v1/exchangerate/" + c.AssetIdBase + "/USD"
-	} else {
-		url = "https://rest.coinapi.io/v1/exchangerate/" + c.AssetIdBase + "/CAD"
-	}
+	url := "https://rest.coinapi.io/v1/exchangerate/" + c.AssetIdBase + "/" + c.AssetIdQuote
 
 	method := "GET"
-	client := &http.Client{}
 	req, err := http.NewRequest(method, url, nil)
 
 	if err != nil {
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// CoinGeckoProvider fetches prices from coingecko by token symbol. The COINGECKO_API_KEY environment
+// variable is optional and sent as a demo api key when set
+type CoinGeckoProvider struct {
+	Client *http.Client
+}
+
+func (p *CoinGeckoProvider) Name() string {
+	return "coingecko"
+}
+
+func (p *CoinGeckoProvider) GetQuote(symbol string, quote string) (float64, error) {
+	symbol = strings.ToLower(symbol)
+	quote = strings.ToLower(quote)
+
+	query := url.Values{}
+	query.Set("symbols", symbol)
+	query.Set("vs_currencies", quote)
+
+	req, err := http.NewRequest("GET", "https://api.coingecko.com/api/v3/simple/price?"+query.Encode(), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Add("Accept", "application/json")
+	if key := os.Getenv("COINGECKO_API_KEY"); key != "" {
+		req.Header.Add("x-cg-demo-api-key", key)
+	}
+
+	res, err := p.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func(Body io.ReadCloser) {
+		err = Body.Close()
+		if err != nil {
+			return
+		}
+	}(res.Body)
+
+	if res.StatusCode != 200 {
+		return 0, errors.New(fmt.Sprintf("coingecko request failed with status code: %s", res.Status))
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	// response is keyed by symbol and then by quote currency, e.g. {"atom":{"usd":4.51}}
+	prices := make(map[string]map[string]float64)
+	if err = json.Unmarshal(body, &prices); err != nil {
+		return 0, err
+	}
+
+	rate, ok := prices[symbol][quote]
+	if !ok {
+		return 0, errors.New(fmt.Sprintf("no %s price for %s", quote, symbol))
+	}
+	return rate, nil
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"strings"
+
+	sdkmath "cosmossdk.io/math"
 )
 
 type RewardsResponse struct {
@@ -29,26 +31,35 @@ type CommissionResponse struct {
 	} `json:"commission"`
 }
 
-func (r *RewardsResponse) GetBalances() map[int]map[string]string {
-	balances := make(map[int]map[string]string)
-	balances[Rewards] = map[string]string{}
+// WithdrawAddressResponse is the address the rewards and commissions of a delegator are withdrawn to
+type WithdrawAddressResponse struct {
+	WithdrawAddress string `json:"withdraw_address"`
+}
+
+func (r *RewardsResponse) GetBalances() (map[int]map[string]sdkmath.Int, error) {
+	balances := make(map[int]map[string]sdkmath.Int)
+	balances[Rewards] = make(map[string]sdkmath.Int)
 
 	for _, rewards := range r.Rewards {
 		for _, reward := range rewards.Reward {
-			balances[Rewards][reward.Denom] = reward.Amount
+			if err := addAmount(balances[Rewards], reward.Denom, reward.Amount); err != nil {
+				return nil, err
+			}
 		}
 	}
-	return balances
+	return balances, nil
 }
 
-func (c *CommissionResponse) GetBalances() map[int]map[string]string {
-	balances := make(map[int]map[string]string)
-	balances[Commission] = map[string]string{}
+func (c *CommissionResponse) GetBalances() (map[int]map[string]sdkmath.Int, error) {
+	balances := make(map[int]map[string]sdkmath.Int)
+	balances[Commission] = make(map[string]sdkmath.Int)
 
 	for _, commission := range c.Commissions.Commission {
-		balances[Commission][commission.Denom] = commission.Amount
+		if err := addAmount(balances[Commission], commission.Denom, commission.Amount); err != nil {
+			return nil, err
+		}
 	}
-	return balances
+	return balances, nil
 }
 
 func (r *RewardsResponse) QueryRewards(address string, endpoint string, client *http.Client) error {
@@ -86,3 +97,20 @@ func (c *CommissionResponse) QueryCommission(validator string, endpoint string,
 	}
 	return err
 }
+
+// QueryWithdrawAddress queries the withdraw address of the delegator, which is the delegator itself unless it was set
+func (w *WithdrawAddressResponse) QueryWithdrawAddress(address string, endpoint string, client *http.Client) error {
+	var body []byte
+
+	url := endpoint + "/cosmos/distribution/v1beta1/delegators/" + address + "/withdraw_address"
+	body, err := HttpGet(url, client)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(body, w)
+	if err != nil {
+		return err
+	}
+	return nil
+}
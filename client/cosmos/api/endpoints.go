@@ -0,0 +1,190 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxBlockAge is the age of the latest block after which an endpoint is considered stale
+const MaxBlockAge = 5 * time.Minute
+
+// MaxHeightLag is the number of blocks an endpoint can be behind the most recent one before it is considered stale
+const MaxHeightLag = 100
+
+// Endpoints are the REST endpoints of a chain, in order of preference. Queries to any of them fail over to
+// the next ones when it cannot be reached
+type Endpoints struct {
+	mu   sync.Mutex
+	urls []string
+}
+
+// EndpointHealth is the result of the health check of an endpoint
+type EndpointHealth struct {
+	Url       string
+	Height    int64
+	BlockTime time.Time
+	Err       error
+}
+
+// Healthy returns true when the endpoint answered with a recent block
+func (h EndpointHealth) Healthy() bool {
+	return h.Err == nil
+}
+
+// registry maps every registered endpoint to the endpoints of its chain
+var registry = struct {
+	mu        sync.RWMutex
+	endpoints map[string]*Endpoints
+}{endpoints: make(map[string]*Endpoints)}
+
+// RegisterEndpoints registers the REST endpoints of a chain, so HttpGet fails over between them.
+// Trailing slashes and duplicates are removed
+func RegisterEndpoints(urls []string) *Endpoints {
+	e := &Endpoints{}
+	seen := make(map[string]bool)
+	for _, url := range urls {
+		url = strings.TrimRight(strings.TrimSpace(url), "/")
+		if url == "" || seen[url] {
+			continue
+		}
+		seen[url] = true
+		e.urls = append(e.urls, url)
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	for _, url := range e.urls {
+		registry.endpoints[url] = e
+	}
+	return e
+}
+
+// Preferred returns the endpoint queries are sent to first, empty when there is none
+func (e *Endpoints) Preferred() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.urls) == 0 {
+		return ""
+	}
+	return e.urls[0]
+}
+
+// URLs returns the endpoints in order of preference
+func (e *Endpoints) URLs() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.urls...)
+}
+
+// promote makes url the preferred endpoint, e.g. after the preferred one failed
+func (e *Endpoints) promote(url string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i, u := range e.urls {
+		if u == url {
+			copy(e.urls[1:i+1], e.urls[:i])
+			e.urls[0] = url
+			return
+		}
+	}
+}
+
+// CheckHealth queries the latest block of every endpoint. Endpoints whose latest block is older than MaxBlockAge
+// or that are more than MaxHeightLag blocks behind are moved after the healthy ones, followed by the endpoints
+// that cannot be reached. Endpoints keep their configured order otherwise
+func (e *Endpoints) CheckHealth(client *http.Client) []EndpointHealth {
+	urls := e.URLs()
+	results := make([]EndpointHealth, len(urls))
+
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			results[i] = latestBlockHealth(url, client)
+		}(i, url)
+	}
+	wg.Wait()
+
+	var highest int64
+	for _, result := range results {
+		if result.Err == nil && result.Height > highest {
+			highest = result.Height
+		}
+	}
+
+	var healthy, stale, unreachable []string
+	for i := range results {
+		if results[i].Err != nil {
+			unreachable = append(unreachable, results[i].Url)
+			continue
+		}
+
+		switch {
+		case time.Since(results[i].BlockTime) > MaxBlockAge:
+			results[i].Err = errors.New(fmt.Sprintf("latest block %d is %s old", results[i].Height, time.Since(results[i].BlockTime).Round(time.Second)))
+		case highest-results[i].Height > MaxHeightLag:
+			results[i].Err = errors.New(fmt.Sprintf("latest block %d is %d blocks behind", results[i].Height, highest-results[i].Height))
+		}
+
+		if results[i].Err == nil {
+			healthy = append(healthy, results[i].Url)
+		} else {
+			stale = append(stale, results[i].Url)
+		}
+	}
+
+	e.mu.Lock()
+	e.urls = append(append(healthy, stale...), unreachable...)
+	e.mu.Unlock()
+	return results
+}
+
+// latestBlockHealth queries the latest block of a single endpoint, without failing over
+func latestBlockHealth(url string, client *http.Client) EndpointHealth {
+	health := EndpointHealth{Url: url}
+
+	body, err := httpGetOnce(url+"/cosmos/base/tendermint/v1beta1/blocks/latest", client)
+	if err != nil {
+		health.Err = err
+		return health
+	}
+
+	var block BlockResponse
+	if err = json.Unmarshal(body, &block); err != nil {
+		health.Err = err
+		return health
+	}
+	if health.Height, err = strconv.ParseInt(block.Block.Header.Height, 10, 64); err != nil {
+		health.Err = errors.New(fmt.Sprintf("invalid block height %s", block.Block.Header.Height))
+		return health
+	}
+	health.BlockTime = block.Block.Header.Time
+	return health
+}
+
+// candidates returns the endpoints to send the query to in order, and the path of the query on them. Queries
+// to an unregistered endpoint are only sent to it
+func candidates(url string) ([]string, string, *Endpoints) {
+	registry.mu.RLock()
+	var base string
+	var endpoints *Endpoints
+	for registered, e := range registry.endpoints {
+		if (url == registered || strings.HasPrefix(url, registered+"/") || strings.HasPrefix(url, registered+"?")) &&
+			len(registered) > len(base) {
+			base, endpoints = registered, e
+		}
+	}
+	registry.mu.RUnlock()
+
+	if endpoints == nil {
+		return []string{url}, "", nil
+	}
+	return endpoints.URLs(), url[len(base):], endpoints
+}
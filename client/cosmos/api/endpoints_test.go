@@ -0,0 +1,256 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// statusServer answers the first failures requests with status and the next ones with body
+func statusServer(t *testing.T, failures int32, status int, body string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	requests := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+// closedURL returns the url of a server that is no longer listening
+func closedURL() string {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	return server.URL
+}
+
+func withoutBackoff(t *testing.T) {
+	backoff := RetryBackoff
+	RetryBackoff = time.Millisecond
+	t.Cleanup(func() { RetryBackoff = backoff })
+}
+
+func TestHttpGetRetry(t *testing.T) {
+	withoutBackoff(t)
+
+	for _, status := range []int{http.StatusServiceUnavailable, http.StatusTooManyRequests} {
+		server, requests := statusServer(t, MaxAttempts-1, status, "ok")
+		body, err := HttpGet(server.URL+"/query", http.DefaultClient)
+		if err != nil || string(body) != "ok" {
+			t.Errorf("%d: HttpGet = %q, %v, want ok", status, body, err)
+		}
+		if requests.Load() != MaxAttempts {
+			t.Errorf("%d: %d requests, want %d", status, requests.Load(), MaxAttempts)
+		}
+	}
+}
+
+func TestHttpGetNotTransient(t *testing.T) {
+	withoutBackoff(t)
+
+	failing, failingRequests := statusServer(t, 1, http.StatusBadRequest, "")
+	other, otherRequests := statusServer(t, 0, 0, "ok")
+	RegisterEndpoints([]string{failing.URL, other.URL})
+
+	body, err := HttpGet(failing.URL+"/query", http.DefaultClient)
+	if err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("err = %v, want the 400 status", err)
+	}
+	if !strings.Contains(string(body), http.StatusText(http.StatusBadRequest)) {
+		t.Errorf("body = %q, want the body of the failed query", body)
+	}
+	// another endpoint would answer the same
+	if failingRequests.Load() != 1 || otherRequests.Load() != 0 {
+		t.Errorf("%d and %d requests, want a single request", failingRequests.Load(), otherRequests.Load())
+	}
+}
+
+func TestTransient(t *testing.T) {
+	untrusted := httptest.NewUnstartedServer(http.NotFoundHandler())
+	untrusted.Config.ErrorLog = log.New(io.Discard, "", 0)
+	untrusted.StartTLS()
+	defer untrusted.Close()
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer slow.Close()
+	closing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	defer closing.Close()
+
+	tests := []struct {
+		name   string
+		url    string
+		client *http.Client
+		want   bool
+	}{
+		{"connection refused", closedURL(), http.DefaultClient, true},
+		{"connection closed", closing.URL, http.DefaultClient, true},
+		{"timeout", slow.URL, &http.Client{Timeout: 50 * time.Millisecond}, true},
+		{"unknown host", "http://stakooler.invalid", http.DefaultClient, true},
+		{"malformed url", "http://[::1", http.DefaultClient, false},
+		{"unsupported scheme", "ftp://127.0.0.1/query", http.DefaultClient, false},
+		{"untrusted certificate", untrusted.URL, http.DefaultClient, false},
+	}
+	for _, test := range tests {
+		_, err := httpGetOnce(test.url, test.client)
+		if err == nil {
+			t.Errorf("%s: query succeeded, want an error", test.name)
+			continue
+		}
+		if got := transient(err); got != test.want {
+			t.Errorf("%s: transient(%v) = %t, want %t", test.name, err, got, test.want)
+		}
+	}
+
+	for status, want := range map[int]bool{
+		http.StatusTooManyRequests:     true,
+		http.StatusBadGateway:          true,
+		http.StatusServiceUnavailable:  true,
+		http.StatusGatewayTimeout:      true,
+		http.StatusBadRequest:          false,
+		http.StatusNotFound:            false,
+		http.StatusInternalServerError: false,
+	} {
+		if got := transient(&StatusError{Code: status}); got != want {
+			t.Errorf("transient(%d) = %t, want %t", status, got, want)
+		}
+	}
+}
+
+func TestHttpGetFailover(t *testing.T) {
+	withoutBackoff(t)
+
+	tests := []struct {
+		name      string
+		preferred func(t *testing.T) (string, *atomic.Int32)
+		attempts  int32
+	}{
+		{"unavailable", func(t *testing.T) (string, *atomic.Int32) {
+			server, requests := statusServer(t, 1000, http.StatusServiceUnavailable, "")
+			return server.URL, requests
+		}, MaxAttempts},
+		{"rate limited", func(t *testing.T) (string, *atomic.Int32) {
+			server, requests := statusServer(t, 1000, http.StatusTooManyRequests, "")
+			return server.URL, requests
+		}, MaxAttempts},
+		{"unreachable", func(t *testing.T) (string, *atomic.Int32) {
+			return closedURL(), nil
+		}, 0},
+	}
+	for _, test := range tests {
+		preferred, preferredRequests := test.preferred(t)
+		fallback, fallbackRequests := statusServer(t, 0, 0, "fallback")
+		endpoints := RegisterEndpoints([]string{preferred + "/", fallback.URL})
+
+		body, err := HttpGet(preferred+"/cosmos/query?x=1", http.DefaultClient)
+		if err != nil || string(body) != "fallback" {
+			t.Errorf("%s: HttpGet = %q, %v, want the answer of the other endpoint", test.name, body, err)
+		}
+		if preferredRequests != nil && preferredRequests.Load() != test.attempts {
+			t.Errorf("%s: %d requests to the failing endpoint, want %d", test.name, preferredRequests.Load(), test.attempts)
+		}
+		if fallbackRequests.Load() != 1 {
+			t.Errorf("%s: %d requests to the other endpoint, want 1", test.name, fallbackRequests.Load())
+		}
+		if endpoints.Preferred() != fallback.URL {
+			t.Errorf("%s: preferred endpoint = %s, want %s", test.name, endpoints.Preferred(), fallback.URL)
+		}
+
+		// the endpoint answering stays the preferred one
+		if _, err = HttpGet(preferred+"/cosmos/query", http.DefaultClient); err != nil || fallbackRequests.Load() != 2 {
+			t.Errorf("%s: next query = %v with %d requests to the preferred endpoint, want 2", test.name, err, fallbackRequests.Load())
+		}
+	}
+}
+
+func TestHttpGetAllEndpointsFail(t *testing.T) {
+	withoutBackoff(t)
+
+	first, _ := statusServer(t, 1000, http.StatusBadGateway, "")
+	second, _ := statusServer(t, 1000, http.StatusGatewayTimeout, "")
+	RegisterEndpoints([]string{first.URL, second.URL})
+
+	if _, err := HttpGet(first.URL+"/query", http.DefaultClient); err == nil || !strings.Contains(err.Error(), "504") {
+		t.Errorf("err = %v, want the error of the last endpoint", err)
+	}
+}
+
+// blockServer answers the latest block query with a block at height produced at blockTime
+func blockServer(t *testing.T, height int64, blockTime time.Time) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/cosmos/base/tendermint/v1beta1/blocks/latest" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"block": {"header": {"height": "%d", "time": "%s"}}}`, height, blockTime.Format(time.RFC3339Nano))
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestCheckHealth(t *testing.T) {
+	now := time.Now()
+	unreachable := closedURL()
+	old := blockServer(t, 1000, now.Add(-MaxBlockAge-time.Minute))
+	behind := blockServer(t, 1000-MaxHeightLag-1, now)
+	slightlyBehind := blockServer(t, 1000-MaxHeightLag, now)
+	latest := blockServer(t, 1000, now)
+	invalid := blockServer(t, 0, now)
+	invalidBody := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"block": {"header": {"height": "abc"}}}`)
+	}))
+	defer invalidBody.Close()
+
+	endpoints := RegisterEndpoints([]string{unreachable, old, behind, invalidBody.URL, slightlyBehind, latest, invalid})
+	results := endpoints.CheckHealth(http.DefaultClient)
+
+	healthy := map[string]bool{slightlyBehind: true, latest: true}
+	if len(results) != 7 {
+		t.Fatalf("%d results, want 7", len(results))
+	}
+	for _, result := range results {
+		if result.Healthy() != healthy[result.Url] {
+			t.Errorf("%s: healthy = %t (%v), want %t", result.Url, result.Healthy(), result.Err, healthy[result.Url])
+		}
+	}
+
+	// healthy endpoints first, then the stale ones and then the unreachable ones, in their configured order
+	want := []string{slightlyBehind, latest, old, behind, invalid, unreachable, invalidBody.URL}
+	got := endpoints.URLs()
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("endpoints = %v, want %v", got, want)
+	}
+	if endpoints.Preferred() != slightlyBehind {
+		t.Errorf("preferred endpoint = %s, want %s", endpoints.Preferred(), slightlyBehind)
+	}
+}
+
+func TestRegisterEndpoints(t *testing.T) {
+	endpoints := RegisterEndpoints([]string{" http://node-a.test/ ", "", "http://node-b.test", "http://node-a.test"})
+	if got := strings.Join(endpoints.URLs(), " "); got != "http://node-a.test http://node-b.test" {
+		t.Errorf("endpoints = %s, want http://node-a.test http://node-b.test", got)
+	}
+
+	bases, path, found := candidates("http://node-b.test/cosmos/bank?x=1")
+	if found != endpoints || path != "/cosmos/bank?x=1" || strings.Join(bases, " ") != "http://node-a.test http://node-b.test" {
+		t.Errorf("candidates = %v %s, want both endpoints and the path", bases, path)
+	}
+	// a host whose name starts with a registered endpoint is another endpoint
+	if bases, _, found = candidates("http://node-a.test.example/cosmos"); found != nil || len(bases) != 1 {
+		t.Errorf("candidates of an unregistered endpoint = %v, want only the endpoint", bases)
+	}
+}
@@ -0,0 +1,209 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrNoVote is returned by the vote queries when the voter has not voted on the proposal
+var ErrNoVote = errors.New("no vote")
+
+// ProposalStatusVotingPeriod is the status of the proposals that can be voted on
+const ProposalStatusVotingPeriod = "PROPOSAL_STATUS_VOTING_PERIOD"
+
+// Proposal is a governance proposal, decoded from the gov/v1 or the gov/v1beta1 queries
+type Proposal struct {
+	Id              string
+	Title           string
+	Status          string
+	VotingStartTime time.Time
+	VotingEndTime   time.Time
+}
+
+type ProposalsResponse struct {
+	Proposals []Proposal
+}
+
+// TallyResult is the number of tokens that voted each option
+type TallyResult struct {
+	Yes        string
+	No         string
+	Abstain    string
+	NoWithVeto string
+}
+
+type TallyResponse struct {
+	Tally TallyResult
+}
+
+// WeightedVoteOption is an option of a vote with the share of the voting power given to it
+type WeightedVoteOption struct {
+	Option string `json:"option"`
+	Weight string `json:"weight"`
+}
+
+type Vote struct {
+	ProposalId string               `json:"proposal_id"`
+	Voter      string               `json:"voter"`
+	Options    []WeightedVoteOption `json:"options"`
+	// Option is only set by the gov/v1beta1 queries of chains that predate weighted votes
+	Option string `json:"option"`
+}
+
+type VoteResponse struct {
+	Vote Vote `json:"vote"`
+}
+
+// proposalV1 is a proposal as returned by the gov/v1 queries. Chains before v0.47 have no title
+type proposalV1 struct {
+	Id              string    `json:"id"`
+	Title           string    `json:"title"`
+	Status          string    `json:"status"`
+	VotingStartTime time.Time `json:"voting_start_time"`
+	VotingEndTime   time.Time `json:"voting_end_time"`
+}
+
+// proposalV1beta1 is a proposal as returned by the gov/v1beta1 queries, its title is in its content
+type proposalV1beta1 struct {
+	ProposalId string `json:"proposal_id"`
+	Content    struct {
+		Title string `json:"title"`
+	} `json:"content"`
+	Status          string    `json:"status"`
+	VotingStartTime time.Time `json:"voting_start_time"`
+	VotingEndTime   time.Time `json:"voting_end_time"`
+}
+
+type tallyV1 struct {
+	YesCount        string `json:"yes_count"`
+	NoCount         string `json:"no_count"`
+	AbstainCount    string `json:"abstain_count"`
+	NoWithVetoCount string `json:"no_with_veto_count"`
+}
+
+type tallyV1beta1 struct {
+	Yes        string `json:"yes"`
+	No         string `json:"no"`
+	Abstain    string `json:"abstain"`
+	NoWithVeto string `json:"no_with_veto"`
+}
+
+// GetOptions returns the options of the vote, votes cast before weighted votes have a single option
+func (v *Vote) GetOptions() []WeightedVoteOption {
+	if len(v.Options) == 0 && v.Option != "" && v.Option != "VOTE_OPTION_UNSPECIFIED" {
+		return []WeightedVoteOption{{Option: v.Option, Weight: "1.000000000000000000"}}
+	}
+	return v.Options
+}
+
+// QueryProposals queries the proposals with the given status, e.g. ProposalStatusVotingPeriod, from the gov/v1
+// queries or from the gov/v1beta1 ones on chains that do not serve gov/v1
+func (p *ProposalsResponse) QueryProposals(status string, endpoint string, client *http.Client) error {
+	url := endpoint + "/cosmos/gov/v1/proposals?proposal_status=" + status
+	err := GetAllPages(url, client, func(body []byte) (Pagination, error) {
+		var page struct {
+			Proposals  []proposalV1 `json:"proposals"`
+			Pagination Pagination   `json:"pagination"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return Pagination{}, err
+		}
+		for _, proposal := range page.Proposals {
+			p.Proposals = append(p.Proposals, Proposal(proposal))
+		}
+		return page.Pagination, nil
+	})
+	if !govV1Missing(err) {
+		return err
+	}
+
+	url = endpoint + "/cosmos/gov/v1beta1/proposals?proposal_status=" + status
+	return GetAllPages(url, client, func(body []byte) (Pagination, error) {
+		var page struct {
+			Proposals  []proposalV1beta1 `json:"proposals"`
+			Pagination Pagination        `json:"pagination"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return Pagination{}, err
+		}
+		for _, proposal := range page.Proposals {
+			p.Proposals = append(p.Proposals, Proposal{
+				Id:              proposal.ProposalId,
+				Title:           proposal.Content.Title,
+				Status:          proposal.Status,
+				VotingStartTime: proposal.VotingStartTime,
+				VotingEndTime:   proposal.VotingEndTime,
+			})
+		}
+		return page.Pagination, nil
+	})
+}
+
+// QueryTally queries the current tally of a proposal in voting period
+func (t *TallyResponse) QueryTally(id string, endpoint string, client *http.Client) error {
+	body, err := HttpGet(endpoint+"/cosmos/gov/v1/proposals/"+id+"/tally", client)
+	if err == nil {
+		var resp struct {
+			Tally tallyV1 `json:"tally"`
+		}
+		if err = json.Unmarshal(body, &resp); err != nil {
+			return err
+		}
+		t.Tally = TallyResult{
+			Yes:        resp.Tally.YesCount,
+			No:         resp.Tally.NoCount,
+			Abstain:    resp.Tally.AbstainCount,
+			NoWithVeto: resp.Tally.NoWithVetoCount,
+		}
+		return nil
+	}
+	if !govV1Missing(err) {
+		return err
+	}
+
+	body, err = HttpGet(endpoint+"/cosmos/gov/v1beta1/proposals/"+id+"/tally", client)
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		Tally tallyV1beta1 `json:"tally"`
+	}
+	if err = json.Unmarshal(body, &resp); err != nil {
+		return err
+	}
+	t.Tally = TallyResult(resp.Tally)
+	return nil
+}
+
+// QueryVote queries the vote of voter on a proposal, it returns ErrNoVote when voter has not voted
+func (v *VoteResponse) QueryVote(id string, voter string, endpoint string, client *http.Client) error {
+	body, err := HttpGet(endpoint+"/cosmos/gov/v1/proposals/"+id+"/votes/"+voter, client)
+	if govV1Missing(err) {
+		body, err = HttpGet(endpoint+"/cosmos/gov/v1beta1/proposals/"+id+"/votes/"+voter, client)
+	}
+	if err != nil {
+		// the node answers 400, or 404 on some versions, with the message of the missing vote
+		var status *StatusError
+		if errors.As(err, &status) && (status.Code == http.StatusBadRequest || status.Code == http.StatusNotFound) &&
+			voteNotFound(string(body)) {
+			return ErrNoVote
+		}
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// voteNotFound returns true when message is the error of the gov module for a voter that has not voted,
+// e.g. voter: cosmos1... not found for proposal: 42
+func voteNotFound(message string) bool {
+	return strings.Contains(message, "not found for proposal")
+}
+
+// govV1Missing returns true when the node does not serve the gov/v1 queries, which were added in v0.46
+func govV1Missing(err error) bool {
+	var status *StatusError
+	return errors.As(err, &status) && status.Code == http.StatusNotImplemented
+}
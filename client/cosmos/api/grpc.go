@@ -0,0 +1,517 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/cmtservice"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	cryptocodec "github.com/cosmos/cosmos-sdk/crypto/codec"
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	vestingtypes "github.com/cosmos/cosmos-sdk/x/auth/vesting/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	distrtypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	govv1 "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	govv1beta1 "github.com/cosmos/cosmos-sdk/x/gov/types/v1beta1"
+	slashingtypes "github.com/cosmos/cosmos-sdk/x/slashing/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/cosmos/gogoproto/proto"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// GrpcTimeout bounds every gRPC query, like the timeout of the http client
+const GrpcTimeout = 10 * time.Second
+
+// interfaceRegistry resolves the accounts and public keys packed in query responses
+var interfaceRegistry = func() codectypes.InterfaceRegistry {
+	registry := codectypes.NewInterfaceRegistry()
+	cryptocodec.RegisterInterfaces(registry)
+	authtypes.RegisterInterfaces(registry)
+	vestingtypes.RegisterInterfaces(registry)
+	return registry
+}()
+
+// GrpcQuerier queries the gRPC endpoint of a node. Responses are marshalled the way the REST gateway does
+// and decoded into the REST responses
+type GrpcQuerier struct {
+	conn   *grpc.ClientConn
+	height string
+}
+
+// NewGrpcQuerier connects to the gRPC endpoint at address (host:port), with TLS when useTLS is set
+func NewGrpcQuerier(address string, useTLS bool) (*GrpcQuerier, error) {
+	creds := insecure.NewCredentials()
+	if useTLS {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+
+	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("connect to %s: %s", address, err))
+	}
+	return &GrpcQuerier{conn: conn}, nil
+}
+
+// context returns the context of a query, querying the state at the height of the querier if it is set
+func (q *GrpcQuerier) context() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), GrpcTimeout)
+	if q.height != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, grpctypes.GRPCBlockHeightHeader, q.height)
+	}
+	return ctx, cancel
+}
+
+func (q *GrpcQuerier) Bech32Prefix(resp *Bech32PrefixResponse) error {
+	ctx, cancel := q.context()
+	defer cancel()
+
+	res, err := authtypes.NewQueryClient(q.conn).Bech32Prefix(ctx, &authtypes.Bech32PrefixRequest{})
+	if err != nil {
+		return grpcError(err)
+	}
+	return toRest(res, resp)
+}
+
+func (q *GrpcQuerier) StakingParams(resp *StakingParamsResponse) error {
+	ctx, cancel := q.context()
+	defer cancel()
+
+	res, err := stakingtypes.NewQueryClient(q.conn).Params(ctx, &stakingtypes.QueryParamsRequest{})
+	if err != nil {
+		return grpcError(err)
+	}
+	return toRest(res, resp)
+}
+
+func (q *GrpcQuerier) LatestBlock(resp *BlockResponse) error {
+	ctx, cancel := q.context()
+	defer cancel()
+
+	res, err := cmtservice.NewServiceClient(q.conn).GetLatestBlock(ctx, &cmtservice.GetLatestBlockRequest{})
+	if err != nil {
+		return grpcError(err)
+	}
+	return toRest(res, resp)
+}
+
+func (q *GrpcQuerier) Block(height string, resp *BlockResponse) error {
+	h, err := strconv.ParseInt(height, 10, 64)
+	if err != nil {
+		return errors.New(fmt.Sprintf("invalid block height %s", height))
+	}
+
+	ctx, cancel := q.context()
+	defer cancel()
+
+	res, err := cmtservice.NewServiceClient(q.conn).GetBlockByHeight(ctx, &cmtservice.GetBlockByHeightRequest{Height: h})
+	if err != nil {
+		return grpcError(err)
+	}
+	return toRest(res, resp)
+}
+
+func (q *GrpcQuerier) ValidatorSet(height string, resp *ValidatorSet) error {
+	h, err := strconv.ParseInt(height, 10, 64)
+	if err != nil {
+		return errors.New(fmt.Sprintf("invalid block height %s", height))
+	}
+
+	// the validator set is paged by offset, see QueryValidatorSet
+	for {
+		ctx, cancel := q.context()
+		page := &query.PageRequest{Offset: uint64(len(resp.Validators)), Limit: ValidatorSetPageLimit}
+		res, err := cmtservice.NewServiceClient(q.conn).GetValidatorSetByHeight(ctx, &cmtservice.GetValidatorSetByHeightRequest{Height: h, Pagination: page})
+		cancel()
+		if err != nil {
+			return grpcError(err)
+		}
+
+		var decoded ValidatorSet
+		if err = toRest(res, &decoded); err != nil {
+			return err
+		}
+		resp.BlockHeight = decoded.BlockHeight
+		resp.Validators = append(resp.Validators, decoded.Validators...)
+		resp.Pagination = decoded.Pagination
+		if lastValidatorSetPage(len(decoded.Validators), len(resp.Validators), decoded.Pagination.Total) {
+			return nil
+		}
+	}
+}
+
+func (q *GrpcQuerier) Account(address string, resp *AcctResponse) error {
+	ctx, cancel := q.context()
+	defer cancel()
+
+	res, err := authtypes.NewQueryClient(q.conn).Account(ctx, &authtypes.QueryAccountRequest{Address: address})
+	if err != nil {
+		return grpcError(err)
+	}
+	if err = toRest(res, resp); err != nil && res.Account != nil {
+		// accounts of types unknown to the SDK (e.g. ethermint accounts) cannot be unpacked, they are
+		// treated as plain accounts
+		log.Debug().Err(err).Msg(fmt.Sprintf("cannot decode account %s of type %s", address, res.Account.TypeUrl))
+		*resp = AcctResponse{}
+		resp.Account.Type = res.Account.TypeUrl
+		return nil
+	}
+	return err
+}
+
+func (q *GrpcQuerier) BankBalances(address string, resp *BankResponse) error {
+	return allPages(q, func(ctx context.Context, page *query.PageRequest) (*query.PageResponse, error) {
+		res, err := banktypes.NewQueryClient(q.conn).AllBalances(ctx, &banktypes.QueryAllBalancesRequest{Address: address, Pagination: page})
+		if err != nil {
+			return nil, err
+		}
+		var decoded BankResponse
+		if err = toRest(res, &decoded); err != nil {
+			return nil, err
+		}
+		resp.Balances = append(resp.Balances, decoded.Balances...)
+		return res.Pagination, nil
+	})
+}
+
+func (q *GrpcQuerier) SpendableBalances(address string, resp *SpendableResponse) error {
+	return allPages(q, func(ctx context.Context, page *query.PageRequest) (*query.PageResponse, error) {
+		res, err := banktypes.NewQueryClient(q.conn).SpendableBalances(ctx, &banktypes.QuerySpendableBalancesRequest{Address: address, Pagination: page})
+		if err != nil {
+			return nil, err
+		}
+		var decoded BankResponse
+		if err = toRest(res, &decoded); err != nil {
+			return nil, err
+		}
+		resp.Balances = append(resp.Balances, decoded.Balances...)
+		return res.Pagination, nil
+	})
+}
+
+func (q *GrpcQuerier) DenomMetadata(denom string, resp *DenomMetadataResponse) error {
+	ctx, cancel := q.context()
+	defer cancel()
+
+	var res proto.Message
+	var err error
+	// This is here because of injective does not implement the original query
+	if strings.HasPrefix(denom, "factory/inj") {
+		res, err = banktypes.NewQueryClient(q.conn).DenomMetadataByQueryString(ctx, &banktypes.QueryDenomMetadataByQueryStringRequest{Denom: denom})
+	} else {
+		res, err = banktypes.NewQueryClient(q.conn).DenomMetadata(ctx, &banktypes.QueryDenomMetadataRequest{Denom: denom})
+	}
+	if err != nil {
+		return grpcError(err)
+	}
+	return toRest(res, resp)
+}
+
+func (q *GrpcQuerier) Rewards(address string, resp *RewardsResponse) error {
+	ctx, cancel := q.context()
+	defer cancel()
+
+	res, err := distrtypes.NewQueryClient(q.conn).DelegationTotalRewards(ctx, &distrtypes.QueryDelegationTotalRewardsRequest{DelegatorAddress: address})
+	if err != nil {
+		return grpcError(err)
+	}
+	return toRest(res, resp)
+}
+
+func (q *GrpcQuerier) Commission(valoper string, resp *CommissionResponse) error {
+	ctx, cancel := q.context()
+	defer cancel()
+
+	res, err := distrtypes.NewQueryClient(q.conn).ValidatorCommission(ctx, &distrtypes.QueryValidatorCommissionRequest{ValidatorAddress: valoper})
+	if err != nil {
+		if strings.Contains(err.Error(), "validator does not exist") {
+			return nil
+		}
+		return grpcError(err)
+	}
+	return toRest(res, resp)
+}
+
+func (q *GrpcQuerier) WithdrawAddress(address string, resp *WithdrawAddressResponse) error {
+	ctx, cancel := q.context()
+	defer cancel()
+
+	res, err := distrtypes.NewQueryClient(q.conn).DelegatorWithdrawAddress(ctx, &distrtypes.QueryDelegatorWithdrawAddressRequest{DelegatorAddress: address})
+	if err != nil {
+		return grpcError(err)
+	}
+	return toRest(res, resp)
+}
+
+func (q *GrpcQuerier) Delegations(address string, resp *Delegations) error {
+	first := true
+	return allPages(q, func(ctx context.Context, page *query.PageRequest) (*query.PageResponse, error) {
+		res, err := stakingtypes.NewQueryClient(q.conn).DelegatorDelegations(ctx, &stakingtypes.QueryDelegatorDelegationsRequest{DelegatorAddr: address, Pagination: page})
+		if err != nil {
+			return nil, err
+		}
+		var decoded Delegations
+		if err = toRest(res, &decoded); err != nil {
+			return nil, err
+		}
+		resp.DelegationResponses = append(resp.DelegationResponses, decoded.DelegationResponses...)
+		if first {
+			resp.Pagination, first = decoded.Pagination, false
+		}
+		return res.Pagination, nil
+	})
+}
+
+func (q *GrpcQuerier) Unbondings(address string, resp *Unbondings) error {
+	return allPages(q, func(ctx context.Context, page *query.PageRequest) (*query.PageResponse, error) {
+		res, err := stakingtypes.NewQueryClient(q.conn).DelegatorUnbondingDelegations(ctx, &stakingtypes.QueryDelegatorUnbondingDelegationsRequest{DelegatorAddr: address, Pagination: page})
+		if err != nil {
+			return nil, err
+		}
+		var decoded Unbondings
+		if err = toRest(res, &decoded); err != nil {
+			return nil, err
+		}
+		resp.UnbondingResponses = append(resp.UnbondingResponses, decoded.UnbondingResponses...)
+		return res.Pagination, nil
+	})
+}
+
+func (q *GrpcQuerier) Redelegations(address string, resp *Redelegations) error {
+	return allPages(q, func(ctx context.Context, page *query.PageRequest) (*query.PageResponse, error) {
+		res, err := stakingtypes.NewQueryClient(q.conn).Redelegations(ctx, &stakingtypes.QueryRedelegationsRequest{DelegatorAddr: address, Pagination: page})
+		if err != nil {
+			return nil, err
+		}
+		var decoded Redelegations
+		if err = toRest(res, &decoded); err != nil {
+			return nil, err
+		}
+		resp.RedelegationResponses = append(resp.RedelegationResponses, decoded.RedelegationResponses...)
+		return res.Pagination, nil
+	})
+}
+
+func (q *GrpcQuerier) Validator(valoper string, resp *ValidatorResponse) error {
+	ctx, cancel := q.context()
+	defer cancel()
+
+	res, err := stakingtypes.NewQueryClient(q.conn).Validator(ctx, &stakingtypes.QueryValidatorRequest{ValidatorAddr: valoper})
+	if err != nil {
+		return grpcError(err)
+	}
+	return toRest(res, resp)
+}
+
+func (q *GrpcQuerier) SlashingParams(resp *SlashingParamsResponse) error {
+	ctx, cancel := q.context()
+	defer cancel()
+
+	res, err := slashingtypes.NewQueryClient(q.conn).Params(ctx, &slashingtypes.QueryParamsRequest{})
+	if err != nil {
+		return grpcError(err)
+	}
+	return toRest(res, resp)
+}
+
+func (q *GrpcQuerier) SigningInfo(consAddress string, resp *SigningInfoResponse) error {
+	ctx, cancel := q.context()
+	defer cancel()
+
+	res, err := slashingtypes.NewQueryClient(q.conn).SigningInfo(ctx, &slashingtypes.QuerySigningInfoRequest{ConsAddress: consAddress})
+	if err != nil {
+		return grpcError(err)
+	}
+	return toRest(res, resp)
+}
+
+// Proposals converts the proposals field by field, their messages and content can be of any type of the chain,
+// which the interface registry cannot marshal
+func (q *GrpcQuerier) Proposals(proposalStatus string, resp *ProposalsResponse) error {
+	err := allPages(q, func(ctx context.Context, page *query.PageRequest) (*query.PageResponse, error) {
+		res, err := govv1.NewQueryClient(q.conn).Proposals(ctx, &govv1.QueryProposalsRequest{ProposalStatus: govv1.ProposalStatus(govv1.ProposalStatus_value[proposalStatus]), Pagination: page})
+		if err != nil {
+			return nil, err
+		}
+		for _, proposal := range res.Proposals {
+			converted := Proposal{Id: strconv.FormatUint(proposal.Id, 10), Title: proposal.Title, Status: proposal.Status.String()}
+			if proposal.VotingStartTime != nil {
+				converted.VotingStartTime = *proposal.VotingStartTime
+			}
+			if proposal.VotingEndTime != nil {
+				converted.VotingEndTime = *proposal.VotingEndTime
+			}
+			resp.Proposals = append(resp.Proposals, converted)
+		}
+		return res.Pagination, nil
+	})
+	if status.Code(err) != codes.Unimplemented {
+		return err
+	}
+
+	return allPages(q, func(ctx context.Context, page *query.PageRequest) (*query.PageResponse, error) {
+		res, err := govv1beta1.NewQueryClient(q.conn).Proposals(ctx, &govv1beta1.QueryProposalsRequest{ProposalStatus: govv1beta1.ProposalStatus(govv1beta1.ProposalStatus_value[proposalStatus]), Pagination: page})
+		if err != nil {
+			return nil, err
+		}
+		for _, proposal := range res.Proposals {
+			resp.Proposals = append(resp.Proposals, Proposal{
+				Id:              strconv.FormatUint(proposal.ProposalId, 10),
+				Title:           contentTitle(proposal.Content),
+				Status:          proposal.Status.String(),
+				VotingStartTime: proposal.VotingStartTime,
+				VotingEndTime:   proposal.VotingEndTime,
+			})
+		}
+		return res.Pagination, nil
+	})
+}
+
+func (q *GrpcQuerier) Tally(id string, resp *TallyResponse) error {
+	proposalId, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return errors.New(fmt.Sprintf("invalid proposal id %s", id))
+	}
+
+	ctx, cancel := q.context()
+	defer cancel()
+
+	res, err := govv1.NewQueryClient(q.conn).TallyResult(ctx, &govv1.QueryTallyResultRequest{ProposalId: proposalId})
+	if err == nil {
+		resp.Tally = TallyResult{Yes: res.Tally.YesCount, No: res.Tally.NoCount, Abstain: res.Tally.AbstainCount, NoWithVeto: res.Tally.NoWithVetoCount}
+		return nil
+	}
+	if status.Code(err) != codes.Unimplemented {
+		return grpcError(err)
+	}
+
+	legacy, err := govv1beta1.NewQueryClient(q.conn).TallyResult(ctx, &govv1beta1.QueryTallyResultRequest{ProposalId: proposalId})
+	if err != nil {
+		return grpcError(err)
+	}
+	resp.Tally = TallyResult{Yes: legacy.Tally.Yes.String(), No: legacy.Tally.No.String(), Abstain: legacy.Tally.Abstain.String(), NoWithVeto: legacy.Tally.NoWithVeto.String()}
+	return nil
+}
+
+func (q *GrpcQuerier) Vote(id string, voter string, resp *VoteResponse) error {
+	proposalId, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return errors.New(fmt.Sprintf("invalid proposal id %s", id))
+	}
+
+	ctx, cancel := q.context()
+	defer cancel()
+
+	resp.Vote = Vote{ProposalId: id, Voter: voter}
+	res, err := govv1.NewQueryClient(q.conn).Vote(ctx, &govv1.QueryVoteRequest{ProposalId: proposalId, Voter: voter})
+	if err == nil {
+		for _, option := range res.Vote.Options {
+			resp.Vote.Options = append(resp.Vote.Options, WeightedVoteOption{Option: option.Option.String(), Weight: option.Weight})
+		}
+		return nil
+	}
+	if status.Code(err) != codes.Unimplemented {
+		return grpcVoteError(err)
+	}
+
+	legacy, err := govv1beta1.NewQueryClient(q.conn).Vote(ctx, &govv1beta1.QueryVoteRequest{ProposalId: proposalId, Voter: voter})
+	if err != nil {
+		return grpcVoteError(err)
+	}
+	for _, option := range legacy.Vote.Options {
+		resp.Vote.Options = append(resp.Vote.Options, WeightedVoteOption{Option: option.Option.String(), Weight: option.Weight.String()})
+	}
+	resp.Vote.Option = legacy.Vote.Option.String()
+	return nil
+}
+
+// contentTitle returns the title of the content of a gov/v1beta1 proposal. Every content type has its title as
+// first field, like the text proposal, and decoding ignores the fields the text proposal does not have
+func contentTitle(content *codectypes.Any) string {
+	if content == nil {
+		return ""
+	}
+	var text govv1beta1.TextProposal
+	if err := text.Unmarshal(content.Value); err != nil {
+		log.Debug().Err(err).Msg(fmt.Sprintf("cannot decode the title of proposal content %s", content.TypeUrl))
+		return ""
+	}
+	return text.Title
+}
+
+// AtHeight returns a querier sharing the connection of q, so closing either closes both
+func (q *GrpcQuerier) AtHeight(height string) Querier {
+	return &GrpcQuerier{conn: q.conn, height: height}
+}
+
+func (q *GrpcQuerier) Close() error {
+	return q.conn.Close()
+}
+
+// allPages queries every page of a list query, following the next key until it is exhausted
+func allPages(q *GrpcQuerier, page func(ctx context.Context, req *query.PageRequest) (*query.PageResponse, error)) error {
+	var key []byte
+	seen := make(map[string]bool)
+	for {
+		ctx, cancel := q.context()
+		pagination, err := page(ctx, &query.PageRequest{Key: key, Limit: PageLimit})
+		cancel()
+		if err != nil {
+			return grpcError(err)
+		}
+
+		if pagination == nil || len(pagination.NextKey) == 0 {
+			return nil
+		}
+		// a node returning a key twice would be paged forever
+		next := base64.StdEncoding.EncodeToString(pagination.NextKey)
+		if seen[next] {
+			return errors.New(fmt.Sprintf("pagination returned key %s twice", next))
+		}
+		seen[next] = true
+		key = pagination.NextKey
+	}
+}
+
+// toRest decodes a gRPC response into the matching REST response
+func toRest(msg proto.Message, resp any) error {
+	body, err := codec.ProtoMarshalJSON(msg, interfaceRegistry)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, resp)
+}
+
+// grpcError maps the status codes callers check to the ones of the REST gateway
+func grpcError(err error) error {
+	if status.Code(err) == codes.NotFound {
+		return errors.New(fmt.Sprintf("%s: %s", &StatusError{Code: http.StatusNotFound}, status.Convert(err).Message()))
+	}
+	return err
+}
+
+// grpcVoteError returns ErrNoVote when the vote query failed because the voter has not voted, the gov module
+// answers InvalidArgument in that case
+func grpcVoteError(err error) error {
+	code := status.Code(err)
+	if (code == codes.InvalidArgument || code == codes.NotFound) && voteNotFound(status.Convert(err).Message()) {
+		return ErrNoVote
+	}
+	return grpcError(err)
+}
@@ -1,19 +1,202 @@
 package api
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"sync"
+	"syscall"
 	"time"
+
+	"github.com/rs/zerolog/log"
 )
 
-func NewHttpClient() (client *http.Client) {
-	client = &http.Client{Timeout: 10 * time.Second}
+// NewHttpClient returns the client used for all queries. When maxPerEndpoint is greater than zero
+// no more than maxPerEndpoint requests are in flight at the same time against a single host
+func NewHttpClient(maxPerEndpoint int) (client *http.Client) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	client = &http.Client{Timeout: 10 * time.Second, Transport: transport}
+
+	if maxPerEndpoint > 0 {
+		transport.MaxIdleConnsPerHost = maxPerEndpoint
+		client.Transport = &endpointLimiter{
+			transport: transport,
+			limit:     maxPerEndpoint,
+			slots:     make(map[string]chan struct{}),
+		}
+	}
 	return
 }
 
+// WithBlockHeight returns a copy of the client that queries the state at the given height
+// by sending the x-cosmos-block-height header with every request
+func WithBlockHeight(client *http.Client, height string) *http.Client {
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	pinned := *client
+	pinned.Transport = &heightTransport{transport: transport, height: height}
+	return &pinned
+}
+
+type heightTransport struct {
+	transport http.RoundTripper
+	height    string
+}
+
+func (h *heightTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("x-cosmos-block-height", h.height)
+	return h.transport.RoundTrip(req)
+}
+
+// endpointLimiter bounds the number of concurrent requests per host
+type endpointLimiter struct {
+	transport http.RoundTripper
+	limit     int
+	mu        sync.Mutex
+	slots     map[string]chan struct{}
+}
+
+func (l *endpointLimiter) RoundTrip(req *http.Request) (*http.Response, error) {
+	l.mu.Lock()
+	slot, ok := l.slots[req.URL.Host]
+	if !ok {
+		slot = make(chan struct{}, l.limit)
+		l.slots[req.URL.Host] = slot
+	}
+	l.mu.Unlock()
+
+	select {
+	case slot <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+
+	res, err := l.transport.RoundTrip(req)
+	if err != nil {
+		<-slot
+		return nil, err
+	}
+
+	// the slot is only released once the body has been read and closed
+	res.Body = &releaseOnClose{ReadCloser: res.Body, release: func() { <-slot }}
+	return res, nil
+}
+
+type releaseOnClose struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
+
+// MaxAttempts is the number of times a query failing with a transient error is sent to an endpoint
+const MaxAttempts = 3
+
+// RetryBackoff is the delay before the first retry, it doubles after every attempt
+var RetryBackoff = 250 * time.Millisecond
+
+// StatusError is returned when a query is answered with a status code other than 200
+type StatusError struct {
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("status code: %d", e.Code)
+}
+
+// transient returns true for errors a retry or another endpoint can solve: network errors, timeouts, rate
+// limiting and unavailable nodes. Anything else, e.g. a malformed url or an invalid certificate, fails fast
+func transient(err error) bool {
+	var status *StatusError
+	if errors.As(err, &status) {
+		switch status.Code {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidCert x509.CertificateInvalidError
+	if errors.As(err, &certErr) || errors.As(err, &unknownAuthority) || errors.As(err, &hostnameErr) || errors.As(err, &invalidCert) {
+		return false
+	}
+
+	// every error of the client is a *url.Error, which is a net.Error, so only the errors it wraps are checked
+	var opErr *net.OpError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET):
+		return true
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		// the connection was closed by the node before answering
+		return true
+	case errors.As(err, &opErr):
+		// dial, DNS and read errors
+		return true
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return true
+	}
+	return false
+}
+
+// HttpGet queries url, retrying transient errors with an exponential backoff. Queries to a registered
+// endpoint fail over to the other endpoints of the chain, the first one answering becomes the preferred one
 func HttpGet(url string, client *http.Client) ([]byte, error) {
+	bases, path, endpoints := candidates(url)
+
+	var body []byte
+	var err error
+	for i, base := range bases {
+		body, err = httpGetWithRetry(base+path, client)
+		if err == nil {
+			if i > 0 {
+				log.Warn().Msg(fmt.Sprintf("%s failed, using %s", bases[0], base))
+				endpoints.promote(base)
+			}
+			return body, nil
+		}
+		if !transient(err) {
+			// any other endpoint would answer the same
+			return body, err
+		}
+		if i < len(bases)-1 {
+			log.Debug().Err(err).Msg(fmt.Sprintf("query %s failed, trying the next endpoint", base+path))
+		}
+	}
+	return body, err
+}
+
+// httpGetWithRetry sends the query up to MaxAttempts times while it fails with a transient error
+func httpGetWithRetry(url string, client *http.Client) ([]byte, error) {
+	backoff := RetryBackoff
+	for attempt := 1; ; attempt++ {
+		body, err := httpGetOnce(url, client)
+		if err == nil || !transient(err) || attempt == MaxAttempts {
+			return body, err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// httpGetOnce sends the query once
+func httpGetOnce(url string, client *http.Client) ([]byte, error) {
 	var req *http.Request
 	var res *http.Response
 	var body []byte
@@ -43,7 +226,7 @@ func HttpGet(url string, client *http.Client) ([]byte, error) {
 	// returning the body even if the status code is not 200
 	// allows the caller to decide if it should stop
 	if res.StatusCode != http.StatusOK {
-		return body, errors.New(fmt.Sprintf("status code: %d", res.StatusCode))
+		return body, &StatusError{Code: res.StatusCode}
 	}
 	return body, nil
 }
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DenomTraceResponse is the origin of an IBC denom: the path of port/channel hops it
+// travelled through and its denom on the chain it was issued on
+type DenomTraceResponse struct {
+	DenomTrace struct {
+		Path      string `json:"path"`
+		BaseDenom string `json:"base_denom"`
+	} `json:"denom_trace"`
+}
+
+// denomResponse is the ibc-go v8 replacement of the denom trace query
+type denomResponse struct {
+	Denom struct {
+		Base  string `json:"base"`
+		Trace []struct {
+			PortId    string `json:"port_id"`
+			ChannelId string `json:"channel_id"`
+		} `json:"trace"`
+	} `json:"denom"`
+}
+
+type ClientStateResponse struct {
+	IdentifiedClientState struct {
+		ClientId    string `json:"client_id"`
+		ClientState struct {
+			ChainId string `json:"chain_id"`
+		} `json:"client_state"`
+	} `json:"identified_client_state"`
+}
+
+// Hop is a port and channel an IBC denom was transferred through
+type Hop struct {
+	PortId    string
+	ChannelId string
+}
+
+// QueryDenomTrace resolves an ibc/<hash> denom, falling back to the denoms query of newer ibc-go versions
+// when the chain does not serve denom traces anymore
+func (d *DenomTraceResponse) QueryDenomTrace(denom string, endpoint string, client *http.Client) error {
+	hash := denom
+	if len(denom) > len("ibc/") && strings.EqualFold(denom[:len("ibc/")], "ibc/") {
+		hash = denom[len("ibc/"):]
+	}
+
+	body, err := HttpGet(endpoint+"/ibc/apps/transfer/v1/denom_traces/"+hash, client)
+	if err == nil {
+		return json.Unmarshal(body, d)
+	}
+
+	body, fallbackErr := HttpGet(endpoint+"/ibc/apps/transfer/v1/denoms/"+hash, client)
+	if fallbackErr != nil {
+		return errors.New(fmt.Sprintf("query denom trace of %s: %s", denom, err))
+	}
+
+	var resp denomResponse
+	if err = json.Unmarshal(body, &resp); err != nil {
+		return err
+	}
+
+	var hops []string
+	for _, hop := range resp.Denom.Trace {
+		hops = append(hops, hop.PortId, hop.ChannelId)
+	}
+	d.DenomTrace.Path = strings.Join(hops, "/")
+	d.DenomTrace.BaseDenom = resp.Denom.Base
+	return nil
+}
+
+// Hops returns the port/channel hops of the trace path, the first one being the channel on the queried chain
+func (d *DenomTraceResponse) Hops() []Hop {
+	parts := strings.Split(d.DenomTrace.Path, "/")
+	var hops []Hop
+	for i := 0; i+1 < len(parts); i += 2 {
+		hops = append(hops, Hop{PortId: parts[i], ChannelId: parts[i+1]})
+	}
+	return hops
+}
+
+// QueryChannelClientState returns the client state of a channel, its chain id being the counterparty chain
+func (c *ClientStateResponse) QueryChannelClientState(port string, channel string, endpoint string, client *http.Client) error {
+	url := endpoint + "/ibc/core/channel/v1/channels/" + channel + "/ports/" + port + "/client_state"
+	body, err := HttpGet(url, client)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, c)
+}
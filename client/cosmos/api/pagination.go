@@ -0,0 +1,62 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PageLimit is the number of items requested per page by list queries
+const PageLimit = 1000
+
+// Pagination is returned by every list query. NextKey is the base64 key of the next page, empty on the last page.
+// Total is only returned on the first page when pagination.count_total is set
+type Pagination struct {
+	NextKey string `json:"next_key"`
+	Total   string `json:"total"`
+}
+
+// GetAllPages queries every page of a list endpoint, following next_key until it is exhausted. page decodes
+// the body of a page, merges its items and returns its pagination
+func GetAllPages(endpointURL string, client *http.Client, page func(body []byte) (Pagination, error)) error {
+	key := ""
+	seen := make(map[string]bool)
+	for {
+		body, err := HttpGet(pageURL(endpointURL, key), client)
+		if err != nil {
+			return err
+		}
+
+		pagination, err := page(body)
+		if err != nil {
+			return err
+		}
+
+		if pagination.NextKey == "" {
+			return nil
+		}
+		// a node returning a key twice would be paged forever
+		if seen[pagination.NextKey] {
+			return errors.New(fmt.Sprintf("pagination of %s returned key %s twice", endpointURL, pagination.NextKey))
+		}
+		seen[pagination.NextKey] = true
+		key = pagination.NextKey
+	}
+}
+
+// pageURL adds the page limit and, after the first page, the key of the page to the query
+func pageURL(endpointURL string, key string) string {
+	separator := "?"
+	if strings.Contains(endpointURL, "?") {
+		separator = "&"
+	}
+
+	pageURL := endpointURL + separator + "pagination.limit=" + strconv.Itoa(PageLimit)
+	if key != "" {
+		pageURL += "&pagination.key=" + url.QueryEscape(key)
+	}
+	return pageURL
+}
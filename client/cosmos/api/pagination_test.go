@@ -0,0 +1,191 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// pagedServer serves the items of a list query in pages of size items, answering the page key "" with the first
+// page and the next key nextKeys[i] for page i. It records the query of every request
+func pagedServer(t *testing.T, items []string, size int, nextKeys []string) (*httptest.Server, *[]string) {
+	t.Helper()
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+
+		page := 0
+		if key := r.URL.Query().Get("pagination.key"); key != "" {
+			page = -1
+			for i, next := range nextKeys {
+				if next == key {
+					page = i + 1
+					break
+				}
+			}
+			if page < 0 {
+				http.Error(w, `{"code": 3, "message": "invalid key"}`, http.StatusBadRequest)
+				return
+			}
+		}
+
+		start, end := page*size, (page+1)*size
+		if end > len(items) {
+			end = len(items)
+		}
+		next := ""
+		if page < len(nextKeys) {
+			next = nextKeys[page]
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"items":      items[start:end],
+			"pagination": map[string]any{"next_key": next, "total": ""},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &queries
+}
+
+func decodeItems(merged *[]string) func(body []byte) (Pagination, error) {
+	return func(body []byte) (Pagination, error) {
+		var page struct {
+			Items      []string   `json:"items"`
+			Pagination Pagination `json:"pagination"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return Pagination{}, err
+		}
+		*merged = append(*merged, page.Items...)
+		return page.Pagination, nil
+	}
+}
+
+func TestGetAllPages(t *testing.T) {
+	items := []string{"a", "b", "c", "d", "e"}
+	tests := []struct {
+		name     string
+		size     int
+		nextKeys []string
+	}{
+		{"single page", 5, nil},
+		{"next key chain", 2, []string{"AAE=", "AAI="}},
+		{"keys needing escaping", 2, []string{"a+b/c==", "d&e=f"}},
+	}
+	for _, test := range tests {
+		server, queries := pagedServer(t, items, test.size, test.nextKeys)
+
+		var merged []string
+		if err := GetAllPages(server.URL+"/items?status=active", http.DefaultClient, decodeItems(&merged)); err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if strings.Join(merged, ",") != strings.Join(items, ",") {
+			t.Errorf("%s: items = %v, want %v", test.name, merged, items)
+		}
+		if len(*queries) != len(test.nextKeys)+1 {
+			t.Errorf("%s: %d pages queried, want %d", test.name, len(*queries), len(test.nextKeys)+1)
+		}
+		for _, query := range *queries {
+			if !strings.HasPrefix(query, "status=active&pagination.limit="+strconv.Itoa(PageLimit)) {
+				t.Errorf("%s: query %s does not keep the query of the url and set the page limit", test.name, query)
+			}
+		}
+	}
+}
+
+func TestGetAllPagesRepeatedKey(t *testing.T) {
+	// the second page returns the key of the first page again, which would be paged forever
+	server, queries := pagedServer(t, []string{"a", "b", "c", "d", "e", "f"}, 2, []string{"AAE=", "AAE=", "AAI="})
+
+	var merged []string
+	err := GetAllPages(server.URL+"/items", http.DefaultClient, decodeItems(&merged))
+	if err == nil || !strings.Contains(err.Error(), "AAE= twice") {
+		t.Errorf("err = %v, want the repeated key", err)
+	}
+	if len(*queries) != 2 {
+		t.Errorf("%d pages queried, want 2", len(*queries))
+	}
+}
+
+func TestGetAllPagesErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("pagination.key") == "" {
+			fmt.Fprint(w, `{"items": ["a"], "pagination": {"next_key": "AAE="}}`)
+			return
+		}
+		http.Error(w, `{"code": 5, "message": "not found"}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	var merged []string
+	err := GetAllPages(server.URL+"/items", http.DefaultClient, decodeItems(&merged))
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("err = %v, want the status of the failed page", err)
+	}
+
+	err = GetAllPages(server.URL+"/items", http.DefaultClient, func(body []byte) (Pagination, error) {
+		return Pagination{}, fmt.Errorf("cannot decode")
+	})
+	if err == nil || err.Error() != "cannot decode" {
+		t.Errorf("err = %v, want the decoding error", err)
+	}
+}
+
+func TestGetValidatorDelegatorCount(t *testing.T) {
+	tests := []struct {
+		name        string
+		delegations int
+		// countTotal is the total the node answers to count_total, nodes that do not count answer "" or "0"
+		countTotal string
+		want       int
+		requests   int
+	}{
+		{"counted", 2500, "2500", 2500, 1},
+		{"no delegation", 0, "0", 0, 1},
+		{"total not returned", 2500, "", 2500, 4},
+		{"total not counted", 2500, "0", 2500, 4},
+	}
+	for _, test := range tests {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			query := r.URL.Query()
+			limit, _ := strconv.Atoi(query.Get("pagination.limit"))
+			start, _ := strconv.Atoi(query.Get("pagination.key"))
+			end := start + limit
+			if end > test.delegations {
+				end = test.delegations
+			}
+
+			delegations := make([]map[string]any, end-start)
+			for i := range delegations {
+				delegations[i] = map[string]any{"balance": map[string]string{"denom": "uatom", "amount": "1"}}
+			}
+			pagination := map[string]string{}
+			if end < test.delegations {
+				pagination["next_key"] = strconv.Itoa(end)
+			}
+			if query.Get("pagination.count_total") == "true" {
+				pagination["total"] = test.countTotal
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"delegation_responses": delegations, "pagination": pagination})
+		}))
+
+		got, err := GetValidatorDelegatorCount(server.URL, "cosmosvaloper1", http.DefaultClient)
+		server.Close()
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s: count = %d, want %d", test.name, got, test.want)
+		}
+		if requests != test.requests {
+			t.Errorf("%s: %d requests, want %d", test.name, requests, test.requests)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// priceCache keeps one price per (symbol, quote, time bucket) so every symbol is only looked up once
+// per run, regardless of how many accounts and chains hold it. Successful lookups can be persisted
+// on disk and reused by later runs until their time bucket expires
+type priceCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	path    string
+	entries map[priceKey]*priceEntry
+}
+
+type priceKey struct {
+	Symbol string
+	Quote  string
+	Bucket int64
+}
+
+type priceEntry struct {
+	ready chan struct{}
+	price Price
+	err   error
+}
+
+// cachedPrice is the on disk representation of a cache entry
+type cachedPrice struct {
+	Symbol string  `json:"symbol"`
+	Quote  string  `json:"quote"`
+	Bucket int64   `json:"bucket"`
+	Rate   float64 `json:"rate"`
+	Source string  `json:"source"`
+}
+
+func newPriceCache(path string, ttl time.Duration) *priceCache {
+	return &priceCache{
+		ttl:     ttl,
+		path:    path,
+		entries: make(map[priceKey]*priceEntry),
+	}
+}
+
+// bucket returns the time bucket prices fetched now belong to. Without a ttl all prices
+// belong to the same bucket, so they are only reused within a run
+func (c *priceCache) bucket(now time.Time) int64 {
+	if c.ttl <= 0 {
+		return 0
+	}
+	return now.Truncate(c.ttl).Unix()
+}
+
+// get returns the cached price for the key, calling fetch once if it is missing. Concurrent
+// callers asking for the same key wait for the first lookup instead of issuing their own
+func (c *priceCache) get(symbol string, quote string, fetch func() (Price, error)) (Price, error) {
+	key := priceKey{Symbol: symbol, Quote: quote, Bucket: c.bucket(time.Now())}
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &priceEntry{ready: make(chan struct{})}
+		c.entries[key] = entry
+	}
+	c.mu.Unlock()
+
+	if ok {
+		<-entry.ready
+		return entry.price, entry.err
+	}
+
+	entry.price, entry.err = fetch()
+	close(entry.ready)
+	return entry.price, entry.err
+}
+
+// load reads the prices persisted on disk that belong to the current time bucket
+func (c *priceCache) load() error {
+	if c.path == "" {
+		return nil
+	}
+
+	body, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var cached []cachedPrice
+	if err = json.Unmarshal(body, &cached); err != nil {
+		return err
+	}
+
+	current := c.bucket(time.Now())
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, p := range cached {
+		if p.Bucket != current {
+			continue
+		}
+
+		entry := &priceEntry{ready: make(chan struct{}), price: Price{Rate: p.Rate, Source: p.Source}}
+		close(entry.ready)
+		c.entries[priceKey{Symbol: p.Symbol, Quote: p.Quote, Bucket: p.Bucket}] = entry
+	}
+	return nil
+}
+
+// save persists the successful lookups of the current time bucket
+func (c *priceCache) save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	current := c.bucket(time.Now())
+	var cached []cachedPrice
+
+	c.mu.Lock()
+	for key, entry := range c.entries {
+		select {
+		case <-entry.ready:
+		default:
+			// lookup still in progress
+			continue
+		}
+
+		if entry.err != nil || key.Bucket != current {
+			continue
+		}
+		cached = append(cached, cachedPrice{
+			Symbol: key.Symbol,
+			Quote:  key.Quote,
+			Bucket: key.Bucket,
+			Rate:   entry.price.Rate,
+			Source: entry.price.Source,
+		})
+	}
+	c.mu.Unlock()
+
+	body, err := json.MarshalIndent(cached, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, body, 0o644)
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PriceFileProvider serves prices from a local file, which allows offline runs and
+// reports using audited prices (e.g. month-end prices).
+//
+// JSON files map symbols to quote currencies: {"ATOM": {"USD": 7.12, "CAD": 9.65}}
+// CSV files have a header and one price per line: symbol,quote,price
+type PriceFileProvider struct {
+	Path   string
+	prices map[string]map[string]float64
+}
+
+func NewPriceFileProvider(path string) (*PriceFileProvider, error) {
+	p := &PriceFileProvider{
+		Path:   path,
+		prices: make(map[string]map[string]float64),
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func(File *os.File) {
+		err = File.Close()
+		if err != nil {
+			return
+		}
+	}(file)
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = p.readJSON(file)
+	case ".csv":
+		err = p.readCSV(file)
+	default:
+		err = errors.New("unsupported price file format, use json or csv")
+	}
+
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("read price file %s: %s", path, err))
+	}
+	return p, nil
+}
+
+func (p *PriceFileProvider) Name() string {
+	return "file"
+}
+
+func (p *PriceFileProvider) GetQuote(symbol string, quote string) (float64, error) {
+	rate, ok := p.prices[strings.ToUpper(symbol)][strings.ToUpper(quote)]
+	if !ok {
+		return 0, errors.New(fmt.Sprintf("no %s price for %s in %s", quote, symbol, p.Path))
+	}
+	return rate, nil
+}
+
+func (p *PriceFileProvider) readJSON(r io.Reader) error {
+	prices := make(map[string]map[string]float64)
+	if err := json.NewDecoder(r).Decode(&prices); err != nil {
+		return err
+	}
+
+	for symbol, quotes := range prices {
+		for quote, rate := range quotes {
+			p.add(symbol, quote, rate)
+		}
+	}
+	return nil
+}
+
+func (p *PriceFileProvider) readCSV(r io.Reader) error {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return err
+	}
+
+	for i, record := range records {
+		// skip the header
+		if i == 0 {
+			continue
+		}
+
+		if len(record) != 3 {
+			return errors.New(fmt.Sprintf("line %d: expected symbol,quote,price", i+1))
+		}
+
+		rate, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if err != nil {
+			return errors.New(fmt.Sprintf("line %d: %s", i+1, err))
+		}
+		p.add(record[0], record[1], rate)
+	}
+	return nil
+}
+
+func (p *PriceFileProvider) add(symbol string, quote string, rate float64) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	quote = strings.ToUpper(strings.TrimSpace(quote))
+
+	if _, ok := p.prices[symbol]; !ok {
+		p.prices[symbol] = make(map[string]float64)
+	}
+	p.prices[symbol][quote] = rate
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// PriceProvider returns the price of one display unit of a token symbol in a quote currency
+type PriceProvider interface {
+	Name() string
+	GetQuote(symbol string, quote string) (float64, error)
+}
+
+// PriceOracle queries its providers in order until one of them returns a price.
+// Prices are cached so each symbol is only looked up once per quote and time bucket
+type PriceOracle struct {
+	Providers []PriceProvider
+	// Currencies are the quote currencies prices are fetched in
+	Currencies []string
+	cache      *priceCache
+}
+
+// NewPriceOracle returns an oracle caching prices in memory. When cacheFile is set prices are
+// persisted there and reused by later runs for up to ttl
+func NewPriceOracle(providers []PriceProvider, currencies []string, cacheFile string, ttl time.Duration) (*PriceOracle, error) {
+	oracle := &PriceOracle{
+		Providers:  providers,
+		Currencies: currencies,
+		cache:      newPriceCache(cacheFile, ttl),
+	}
+
+	if err := oracle.cache.load(); err != nil {
+		return nil, errors.New(fmt.Sprintf("load price cache %s: %s", cacheFile, err))
+	}
+	return oracle, nil
+}
+
+// SaveCache persists the prices looked up during the run when a cache file is configured
+func (o *PriceOracle) SaveCache() error {
+	if o.cache == nil {
+		return nil
+	}
+	return o.cache.save()
+}
+
+// KeepCacheInMemory drops the prices loaded from the cache file and stops persisting lookups, so the prices
+// of a run are not mixed with the current ones of other runs (e.g. when valuing a past snapshot)
+func (o *PriceOracle) KeepCacheInMemory() {
+	o.cache = newPriceCache("", 0)
+}
+
+// Price is a rate along with the name of the provider that supplied it
+type Price struct {
+	Rate   float64
+	Source string
+}
+
+func (o *PriceOracle) GetQuote(symbol string, quote string) (Price, error) {
+	fetch := func() (Price, error) {
+		price, err := o.fetchQuote(symbol, quote)
+		if err != nil {
+			log.Error().Err(err).Msg(fmt.Sprintf("failed fetching %s price for %s", quote, symbol))
+		}
+		return price, err
+	}
+
+	if o.cache == nil {
+		return fetch()
+	}
+	return o.cache.get(symbol, quote, fetch)
+}
+
+func (o *PriceOracle) fetchQuote(symbol string, quote string) (Price, error) {
+	var errs []string
+	for _, provider := range o.Providers {
+		rate, err := provider.GetQuote(symbol, quote)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", provider.Name(), err))
+			continue
+		}
+		return Price{Rate: rate, Source: provider.Name()}, nil
+	}
+
+	if len(errs) == 0 {
+		return Price{}, errors.New("no price provider configured")
+	}
+	return Price{}, errors.New(strings.Join(errs, "; "))
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"net/http"
+)
+
+// Querier queries the modules of a chain. Responses are decoded into the REST responses whatever the transport,
+// so callers do not depend on it
+type Querier interface {
+	Bech32Prefix(resp *Bech32PrefixResponse) error
+	StakingParams(resp *StakingParamsResponse) error
+	LatestBlock(resp *BlockResponse) error
+	Block(height string, resp *BlockResponse) error
+	ValidatorSet(height string, resp *ValidatorSet) error
+	Account(address string, resp *AcctResponse) error
+	BankBalances(address string, resp *BankResponse) error
+	SpendableBalances(address string, resp *SpendableResponse) error
+	DenomMetadata(denom string, resp *DenomMetadataResponse) error
+	Rewards(address string, resp *RewardsResponse) error
+	Commission(valoper string, resp *CommissionResponse) error
+	WithdrawAddress(address string, resp *WithdrawAddressResponse) error
+	Delegations(address string, resp *Delegations) error
+	Unbondings(address string, resp *Unbondings) error
+	Redelegations(address string, resp *Redelegations) error
+	Validator(valoper string, resp *ValidatorResponse) error
+	SlashingParams(resp *SlashingParamsResponse) error
+	SigningInfo(consAddress string, resp *SigningInfoResponse) error
+	Proposals(status string, resp *ProposalsResponse) error
+	Tally(id string, resp *TallyResponse) error
+	Vote(id string, voter string, resp *VoteResponse) error
+	// AtHeight returns a querier querying the state at the given height
+	AtHeight(height string) Querier
+	// Close releases the connection of the querier, if any
+	Close() error
+}
+
+// RestQuerier queries the REST gateway of a node
+type RestQuerier struct {
+	Endpoint string
+	Client   *http.Client
+}
+
+func NewRestQuerier(endpoint string, client *http.Client) *RestQuerier {
+	return &RestQuerier{Endpoint: endpoint, Client: client}
+}
+
+func (q *RestQuerier) Bech32Prefix(resp *Bech32PrefixResponse) error {
+	return resp.GetPrefix(q.Endpoint, q.Client)
+}
+
+func (q *RestQuerier) StakingParams(resp *StakingParamsResponse) error {
+	return resp.QueryParams(q.Endpoint, q.Client)
+}
+
+func (q *RestQuerier) LatestBlock(resp *BlockResponse) error {
+	return resp.GetLatestBlock(q.Endpoint, q.Client)
+}
+
+func (q *RestQuerier) Block(height string, resp *BlockResponse) error {
+	return resp.GetBlock(height, q.Endpoint, q.Client)
+}
+
+func (q *RestQuerier) ValidatorSet(height string, resp *ValidatorSet) error {
+	return resp.QueryValidatorSet(height, q.Endpoint, q.Client)
+}
+
+func (q *RestQuerier) Account(address string, resp *AcctResponse) error {
+	return resp.QueryAuth(address, q.Endpoint, q.Client)
+}
+
+func (q *RestQuerier) BankBalances(address string, resp *BankResponse) error {
+	return resp.QueryBankBalances(address, q.Endpoint, q.Client)
+}
+
+func (q *RestQuerier) SpendableBalances(address string, resp *SpendableResponse) error {
+	return resp.QuerySpendableBalances(address, q.Endpoint, q.Client)
+}
+
+func (q *RestQuerier) DenomMetadata(denom string, resp *DenomMetadataResponse) error {
+	return resp.QueryMetadataFromBank(denom, q.Endpoint, q.Client)
+}
+
+func (q *RestQuerier) Rewards(address string, resp *RewardsResponse) error {
+	return resp.QueryRewards(address, q.Endpoint, q.Client)
+}
+
+func (q *RestQuerier) Commission(valoper string, resp *CommissionResponse) error {
+	return resp.QueryCommission(valoper, q.Endpoint, q.Client)
+}
+
+func (q *RestQuerier) WithdrawAddress(address string, resp *WithdrawAddressResponse) error {
+	return resp.QueryWithdrawAddress(address, q.Endpoint, q.Client)
+}
+
+func (q *RestQuerier) Delegations(address string, resp *Delegations) error {
+	return resp.QueryDelegations(address, q.Endpoint, q.Client)
+}
+
+func (q *RestQuerier) Unbondings(address string, resp *Unbondings) error {
+	return resp.QueryUnbondings(address, q.Endpoint, q.Client)
+}
+
+func (q *RestQuerier) Redelegations(address string, resp *Redelegations) error {
+	return resp.QueryRedelegations(address, q.Endpoint, q.Client)
+}
+
+func (q *RestQuerier) Validator(valoper string, resp *ValidatorResponse) error {
+	return resp.QueryValidator(valoper, q.Endpoint, q.Client)
+}
+
+func (q *RestQuerier) SlashingParams(resp *SlashingParamsResponse) error {
+	return resp.QueryParams(q.Endpoint, q.Client)
+}
+
+func (q *RestQuerier) SigningInfo(consAddress string, resp *SigningInfoResponse) error {
+	return resp.QuerySigningInfo(consAddress, q.Endpoint, q.Client)
+}
+
+func (q *RestQuerier) Proposals(status string, resp *ProposalsResponse) error {
+	return resp.QueryProposals(status, q.Endpoint, q.Client)
+}
+
+func (q *RestQuerier) Tally(id string, resp *TallyResponse) error {
+	return resp.QueryTally(id, q.Endpoint, q.Client)
+}
+
+func (q *RestQuerier) Vote(id string, voter string, resp *VoteResponse) error {
+	return resp.QueryVote(id, voter, q.Endpoint, q.Client)
+}
+
+func (q *RestQuerier) AtHeight(height string) Querier {
+	return &RestQuerier{Endpoint: q.Endpoint, Client: WithBlockHeight(q.Client, height)}
+}
+
+func (q *RestQuerier) Close() error {
+	return nil
+}
@@ -1,13 +1,51 @@
 package api
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	sdkmath "cosmossdk.io/math"
+)
+
 const OriginalVesting = 0
 const DelegatedVesting = 1
+const DelegatedFree = 2
 const Bank = 3
 const Rewards = 4
 const Commission = 5
 const Delegation = 6
 const Unbonding = 7
+const Spendable = 8
+const Redelegation = 9
 
+// AccountQueryResponse is implemented by every query response holding balances of an account.
+// Balances are returned per balance type and denom in base units
 type AccountQueryResponse interface {
-	GetBalances() map[int]map[string]string
+	GetBalances() (map[int]map[string]sdkmath.Int, error)
+}
+
+// addAmount adds an amount of base units to the balance of the denom. Decimal amounts (e.g. rewards and
+// commissions) are truncated since fractions of a base unit cannot be withdrawn
+func addAmount(balances map[string]sdkmath.Int, denom string, amount string) error {
+	var parsed sdkmath.Int
+	if strings.Contains(amount, ".") {
+		dec, err := sdkmath.LegacyNewDecFromStr(amount)
+		if err != nil {
+			return errors.New(fmt.Sprintf("cannot parse amount %s for %s: %s", amount, denom, err))
+		}
+		parsed = dec.TruncateInt()
+	} else {
+		var ok bool
+		if parsed, ok = sdkmath.NewIntFromString(amount); !ok {
+			return errors.New(fmt.Sprintf("cannot parse amount %s for %s", amount, denom))
+		}
+	}
+
+	if current, ok := balances[denom]; ok {
+		balances[denom] = current.Add(parsed)
+	} else {
+		balances[denom] = parsed
+	}
+	return nil
 }
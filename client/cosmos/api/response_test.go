@@ -0,0 +1,46 @@
+package api
+
+import (
+	"testing"
+
+	sdkmath "cosmossdk.io/math"
+)
+
+func TestAddAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		amounts []string
+		want    string
+	}{
+		{"integer", []string{"1000000"}, "1000000"},
+		{"sum", []string{"1000000", "2500000"}, "3500000"},
+		{"18 decimals", []string{"1000000000000000001", "999999999999999999"}, "2000000000000000000"},
+		{"above uint64", []string{"123456789012345678901234567890"}, "123456789012345678901234567890"},
+		// rewards and commissions are decimal amounts of base units, fractions are truncated
+		{"decimal", []string{"1234.999999999999999999"}, "1234"},
+		{"18 decimals reward", []string{"1000000000000000001.500000000000000000", "1.5"}, "1000000000000000002"},
+	}
+	for _, test := range tests {
+		balances := make(map[string]sdkmath.Int)
+		for _, amount := range test.amounts {
+			if err := addAmount(balances, "aevmos", amount); err != nil {
+				t.Fatalf("%s: addAmount(%s): %s", test.name, amount, err)
+			}
+		}
+		if got := balances["aevmos"].String(); got != test.want {
+			t.Errorf("%s: balance = %s, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestAddAmountInvalid(t *testing.T) {
+	for _, amount := range []string{"", "abc", "1.2.3", "1e18"} {
+		balances := make(map[string]sdkmath.Int)
+		if err := addAmount(balances, "uatom", amount); err == nil {
+			t.Errorf("addAmount(%q) succeeded, want an error", amount)
+		}
+		if _, ok := balances["uatom"]; ok {
+			t.Errorf("addAmount(%q) set a balance", amount)
+		}
+	}
+}
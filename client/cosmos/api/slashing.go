@@ -0,0 +1,64 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+type SlashingParamsResponse struct {
+	Params struct {
+		SignedBlocksWindow      string `json:"signed_blocks_window"`
+		MinSignedPerWindow      string `json:"min_signed_per_window"`
+		DowntimeJailDuration    string `json:"downtime_jail_duration"`
+		SlashFractionDoubleSign string `json:"slash_fraction_double_sign"`
+		SlashFractionDowntime   string `json:"slash_fraction_downtime"`
+	} `json:"params"`
+}
+
+// SigningInfo is the liveness of a validator over the signed blocks window
+type SigningInfo struct {
+	Address             string    `json:"address"`
+	StartHeight         string    `json:"start_height"`
+	IndexOffset         string    `json:"index_offset"`
+	JailedUntil         time.Time `json:"jailed_until"`
+	Tombstoned          bool      `json:"tombstoned"`
+	MissedBlocksCounter string    `json:"missed_blocks_counter"`
+}
+
+type SigningInfoResponse struct {
+	ValSigningInfo SigningInfo `json:"val_signing_info"`
+}
+
+func (p *SlashingParamsResponse) QueryParams(endpoint string, client *http.Client) error {
+	var body []byte
+
+	url := endpoint + "/cosmos/slashing/v1beta1/params"
+	body, err := HttpGet(url, client)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(body, p)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// QuerySigningInfo queries the signing info of the validator with the given consensus address
+func (s *SigningInfoResponse) QuerySigningInfo(consAddress string, endpoint string, client *http.Client) error {
+	var body []byte
+
+	url := endpoint + "/cosmos/slashing/v1beta1/signing_infos/" + consAddress
+	body, err := HttpGet(url, client)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(body, s)
+	if err != nil {
+		return err
+	}
+	return nil
+}
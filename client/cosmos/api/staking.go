@@ -2,10 +2,11 @@ package api
 
 import (
 	"encoding/json"
-	"fmt"
-	"io"
 	"net/http"
+	"strconv"
 	"time"
+
+	sdkmath "cosmossdk.io/math"
 )
 
 type StakingParamsResponse struct {
@@ -30,47 +31,47 @@ type ValidatorSet struct {
 		VotingPower      string `json:"voting_power"`
 		ProposerPriority string `json:"proposer_priority"`
 	} `json:"validators"`
-	Pagination struct {
-		NextKey interface{} `json:"next_key"`
-		Total   string      `json:"total"`
-	} `json:"pagination"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// ValidatorInfo is a validator as returned by the staking module
+type ValidatorInfo struct {
+	OperatorAddress string `json:"operator_address"`
+	ConsensusPubkey struct {
+		Type string `json:"@type"`
+		Key  string `json:"key"`
+	} `json:"consensus_pubkey"`
+	Jailed          bool   `json:"jailed"`
+	Status          string `json:"status"`
+	Tokens          string `json:"tokens"`
+	DelegatorShares string `json:"delegator_shares"`
+	Description     struct {
+		Moniker         string `json:"moniker"`
+		Identity        string `json:"identity"`
+		Website         string `json:"website"`
+		SecurityContact string `json:"security_contact"`
+		Details         string `json:"details"`
+	} `json:"description"`
+	UnbondingHeight string    `json:"unbonding_height"`
+	UnbondingTime   time.Time `json:"unbonding_time"`
+	Commission      struct {
+		CommissionRates struct {
+			Rate          string `json:"rate"`
+			MaxRate       string `json:"max_rate"`
+			MaxChangeRate string `json:"max_change_rate"`
+		} `json:"commission_rates"`
+		UpdateTime time.Time `json:"update_time"`
+	} `json:"commission"`
+	MinSelfDelegation string `json:"min_self_delegation"`
 }
 
 type Validators struct {
-	BlockHeight        string `json:"block_height,omitempty"`
-	ValidatorsResponse []struct {
-		OperatorAddress string `json:"operator_address"`
-		ConsensusPubkey struct {
-			Type string `json:"@type"`
-			Key  string `json:"key"`
-		} `json:"consensus_pubkey"`
-		Jailed          bool   `json:"jailed"`
-		Status          string `json:"status"`
-		Tokens          string `json:"tokens"`
-		DelegatorShares string `json:"delegator_shares"`
-		Description     struct {
-			Moniker         string `json:"moniker"`
-			Identity        string `json:"identity"`
-			Website         string `json:"website"`
-			SecurityContact string `json:"security_contact"`
-			Details         string `json:"details"`
-		} `json:"description"`
-		UnbondingHeight string    `json:"unbonding_height"`
-		UnbondingTime   time.Time `json:"unbonding_time"`
-		Commission      struct {
-			CommissionRates struct {
-				Rate          string `json:"rate"`
-				MaxRate       string `json:"max_rate"`
-				MaxChangeRate string `json:"max_change_rate"`
-			} `json:"commission_rates"`
-			UpdateTime time.Time `json:"update_time"`
-		} `json:"commission"`
-		MinSelfDelegation string `json:"min_self_delegation"`
-	} `json:"validators"`
-	Pagination struct {
-		NextKey interface{} `json:"next_key"`
-		Total   string      `json:"total"`
-	} `json:"pagination"`
+	ValidatorsResponse []ValidatorInfo `json:"validators"`
+	Pagination         Pagination      `json:"pagination"`
+}
+
+type ValidatorResponse struct {
+	Validator ValidatorInfo `json:"validator"`
 }
 
 type Delegations struct {
@@ -85,13 +86,12 @@ type Delegations struct {
 			Amount string `json:"amount"`
 		} `json:"balance"`
 	} `json:"delegation_responses"`
-	Pagination struct {
-		NextKey interface{} `json:"next_key"`
-		Total   string      `json:"total"`
-	} `json:"pagination"`
+	Pagination Pagination `json:"pagination"`
 }
 
 type Unbondings struct {
+	// Denom of the unbonding entries (the bond denom), since the query does not return it
+	Denom              string `json:"-"`
 	UnbondingResponses []struct {
 		DelegatorAddress string `json:"delegator_address"`
 		ValidatorAddress string `json:"validator_address"`
@@ -102,64 +102,122 @@ type Unbondings struct {
 			Balance        string    `json:"balance"`
 		} `json:"entries"`
 	} `json:"unbonding_responses"`
-	Pagination struct {
-		NextKey interface{} `json:"next_key"`
-		Total   string      `json:"total"`
-	} `json:"pagination"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// Redelegations are the redelegations of a delegator still in flight, their balances are already part of the
+// delegations to the destination validators
+type Redelegations struct {
+	// Denom of the redelegation entries (the bond denom), since the query does not return it
+	Denom                 string `json:"-"`
+	RedelegationResponses []struct {
+		Redelegation struct {
+			DelegatorAddress    string `json:"delegator_address"`
+			ValidatorSrcAddress string `json:"validator_src_address"`
+			ValidatorDstAddress string `json:"validator_dst_address"`
+		} `json:"redelegation"`
+		Entries []struct {
+			RedelegationEntry struct {
+				CreationHeight string    `json:"creation_height"`
+				CompletionTime time.Time `json:"completion_time"`
+				InitialBalance string    `json:"initial_balance"`
+				SharesDst      string    `json:"shares_dst"`
+			} `json:"redelegation_entry"`
+			Balance string `json:"balance"`
+		} `json:"entries"`
+	} `json:"redelegation_responses"`
+	Pagination Pagination `json:"pagination"`
 }
 
-func (d *Delegations) GetBalances() map[int]map[string]string {
-	balances := make(map[int]map[string]string)
-	balances[Delegation] = make(map[string]string)
+func (d *Delegations) GetBalances() (map[int]map[string]sdkmath.Int, error) {
+	balances := make(map[int]map[string]sdkmath.Int)
+	balances[Delegation] = make(map[string]sdkmath.Int)
 
 	for _, balance := range d.DelegationResponses {
-		balances[Delegation][balance.Balance.Denom] = balance.Balance.Amount
+		if err := addAmount(balances[Delegation], balance.Balance.Denom, balance.Balance.Amount); err != nil {
+			return nil, err
+		}
 	}
-	return balances
+	return balances, nil
 }
 
-func (u *Unbondings) GetBalances() map[int]map[string]string {
-	balances := make(map[int]map[string]string)
-	balances[Unbonding] = make(map[string]string)
+func (u *Unbondings) GetBalances() (map[int]map[string]sdkmath.Int, error) {
+	balances := make(map[int]map[string]sdkmath.Int)
+	balances[Unbonding] = make(map[string]sdkmath.Int)
 
 	for _, response := range u.UnbondingResponses {
 		for _, entry := range response.Entries {
-			balances[Unbonding]["denom"] = entry.Balance
+			if err := addAmount(balances[Unbonding], u.Denom, entry.Balance); err != nil {
+				return nil, err
+			}
 		}
 	}
-	return balances
+	return balances, nil
 }
 
-func (d *Delegations) QueryDelegations(address string, endpoint string, client *http.Client) error {
-	var body []byte
+func (r *Redelegations) GetBalances() (map[int]map[string]sdkmath.Int, error) {
+	balances := make(map[int]map[string]sdkmath.Int)
+	balances[Redelegation] = make(map[string]sdkmath.Int)
 
-	url := endpoint + "/cosmos/staking/v1beta1/delegations/" + address
-	body, err := HttpGet(url, client)
-	if err != nil {
-		return err
+	for _, response := range r.RedelegationResponses {
+		for _, entry := range response.Entries {
+			if err := addAmount(balances[Redelegation], r.Denom, entry.Balance); err != nil {
+				return nil, err
+			}
+		}
 	}
+	return balances, nil
+}
 
-	err = json.Unmarshal(body, d)
-	if err != nil {
-		return err
-	}
-	return err
+func (d *Delegations) QueryDelegations(address string, endpoint string, client *http.Client) error {
+	url := endpoint + "/cosmos/staking/v1beta1/delegations/" + address
+	return d.queryAllPages(url, client)
 }
 
 func (u *Unbondings) QueryUnbondings(address string, endpoint string, client *http.Client) error {
-	var body []byte
-
 	url := endpoint + "/cosmos/staking/v1beta1/delegators/" + address + "/unbonding_delegations"
-	body, err := HttpGet(url, client)
-	if err != nil {
-		return err
-	}
+	return u.queryAllPages(url, client)
+}
 
-	err = json.Unmarshal(body, u)
-	if err != nil {
-		return err
-	}
-	return nil
+// QueryRedelegations queries the redelegations of the delegator from any source to any destination validator
+func (r *Redelegations) QueryRedelegations(address string, endpoint string, client *http.Client) error {
+	url := endpoint + "/cosmos/staking/v1beta1/delegators/" + address + "/redelegations"
+	return GetAllPages(url, client, func(body []byte) (Pagination, error) {
+		var page Redelegations
+		if err := json.Unmarshal(body, &page); err != nil {
+			return Pagination{}, err
+		}
+		r.RedelegationResponses = append(r.RedelegationResponses, page.RedelegationResponses...)
+		return page.Pagination, nil
+	})
+}
+
+// queryAllPages merges the delegations of every page, the pagination total is the one of the first page
+func (d *Delegations) queryAllPages(url string, client *http.Client) error {
+	first := true
+	return GetAllPages(url, client, func(body []byte) (Pagination, error) {
+		var page Delegations
+		if err := json.Unmarshal(body, &page); err != nil {
+			return Pagination{}, err
+		}
+		d.DelegationResponses = append(d.DelegationResponses, page.DelegationResponses...)
+		if first {
+			d.Pagination, first = page.Pagination, false
+		}
+		return page.Pagination, nil
+	})
+}
+
+// queryAllPages merges the unbondings of every page
+func (u *Unbondings) queryAllPages(url string, client *http.Client) error {
+	return GetAllPages(url, client, func(body []byte) (Pagination, error) {
+		var page Unbondings
+		if err := json.Unmarshal(body, &page); err != nil {
+			return Pagination{}, err
+		}
+		u.UnbondingResponses = append(u.UnbondingResponses, page.UnbondingResponses...)
+		return page.Pagination, nil
+	})
 }
 
 func (p *StakingParamsResponse) QueryParams(chainEndpoint string, client *http.Client) error {
@@ -178,102 +236,69 @@ func (p *StakingParamsResponse) QueryParams(chainEndpoint string, client *http.C
 	return err
 }
 
-func GetChainValidators(endpoint string) (Validators, error) {
-	var validators Validators
-
-	url := endpoint + "/cosmos/staking/v1beta1/validators?pagination.limit=1000&pagination.count_total=true&status=BOND_STATUS_BONDED"
-	method := "GET"
-
-	client := &http.Client{}
-	req, err := http.NewRequest(method, url, nil)
+func (v *ValidatorResponse) QueryValidator(valoper string, endpoint string, client *http.Client) error {
+	var body []byte
 
+	url := endpoint + "/cosmos/staking/v1beta1/validators/" + valoper
+	body, err := HttpGet(url, client)
 	if err != nil {
-		return validators, err
-	}
-	res, err := client.Do(req)
-	if err != nil {
-		return validators, err
+		return err
 	}
-	defer res.Body.Close()
 
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		fmt.Println(err)
-		return validators, err
-	}
-	err = json.Unmarshal(body, &validators)
+	err = json.Unmarshal(body, v)
 	if err != nil {
-		fmt.Println(err)
-		return validators, err
+		return err
 	}
+	return nil
+}
 
-	// Add block height
-	validators.BlockHeight = res.Header.Get("Grpc-Metadata-X-Cosmos-Block-Height")
+func GetChainValidators(endpoint string, client *http.Client) (Validators, error) {
+	var validators Validators
 
-	return validators, nil
+	url := endpoint + "/cosmos/staking/v1beta1/validators?status=BOND_STATUS_BONDED"
+	err := GetAllPages(url, client, func(body []byte) (Pagination, error) {
+		var page Validators
+		if err := json.Unmarshal(body, &page); err != nil {
+			return Pagination{}, err
+		}
+		validators.ValidatorsResponse = append(validators.ValidatorsResponse, page.ValidatorsResponse...)
+		return page.Pagination, nil
+	})
+	return validators, err
 }
 
-func GetValidatorUnbondings(endpoint string, address string) (Unbondings, error) {
+func GetValidatorUnbondings(endpoint string, address string, client *http.Client) (Unbondings, error) {
 	var unbondings Unbondings
-	url := endpoint + "/cosmos/staking/v1beta1/validators/" + address + "/unbonding_delegations"
-	method := "GET"
 
-	client := &http.Client{}
-	req, err := http.NewRequest(method, url, nil)
+	url := endpoint + "/cosmos/staking/v1beta1/validators/" + address + "/unbonding_delegations"
+	err := unbondings.queryAllPages(url, client)
+	return unbondings, err
+}
 
+// GetValidatorDelegatorCount returns the number of delegations of a validator. A single delegation is queried with
+// its total counted, nodes that do not count the total are paged through every delegation instead
+func GetValidatorDelegatorCount(endpoint string, valoper string, client *http.Client) (int, error) {
+	url := endpoint + "/cosmos/staking/v1beta1/validators/" + valoper + "/delegations"
+	body, err := HttpGet(url+"?pagination.limit=1&pagination.count_total=true", client)
 	if err != nil {
-		fmt.Println(err)
-		return unbondings, err
-	}
-	res, err := client.Do(req)
-	if err != nil {
-		fmt.Println(err)
-		return unbondings, err
+		return 0, err
 	}
-	defer res.Body.Close()
 
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		fmt.Println(err)
-		return unbondings, err
-	}
-	err = json.Unmarshal(body, &unbondings)
-	if err != nil {
-		fmt.Println(err)
-		return unbondings, err
+	var first Delegations
+	if err = json.Unmarshal(body, &first); err != nil {
+		return 0, err
 	}
-	return unbondings, nil
-}
-
-func GetValidatorDelegations(endpoint string, valoper string) (Delegations, error) {
-	var delegations Delegations
-
-	url := endpoint + "/cosmos/staking/v1beta1/validators/" + valoper + "/delegations?pagination.limit=15000&pagination.count_total=true"
-	method := "GET"
-
-	client := &http.Client{}
-	req, err := http.NewRequest(method, url, nil)
-
-	if err != nil {
-		fmt.Println(err)
-		return delegations, err
+	if len(first.DelegationResponses) == 0 && first.Pagination.NextKey == "" {
+		return 0, nil
 	}
-	res, err := client.Do(req)
-	if err != nil {
-		fmt.Println(err)
-		return delegations, err
+	if total, err := strconv.Atoi(first.Pagination.Total); err == nil && total > 0 {
+		return total, nil
 	}
-	defer res.Body.Close()
 
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		fmt.Println(err)
-		return delegations, err
-	}
-	err = json.Unmarshal(body, &delegations)
-	if err != nil {
-		fmt.Println(err)
-		return delegations, err
+	// the node ignored count_total, which it answers with an empty or zero total
+	var delegations Delegations
+	if err = delegations.queryAllPages(url, client); err != nil {
+		return 0, err
 	}
-	return delegations, nil
+	return len(delegations.DelegationResponses), nil
 }
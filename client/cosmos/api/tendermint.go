@@ -2,8 +2,11 @@ package api
 
 import (
 	"encoding/json"
-	"io"
+	"errors"
+	"fmt"
 	"net/http"
+	"regexp"
+	"strconv"
 	"time"
 )
 
@@ -176,16 +179,20 @@ type BlockResponse struct {
 					Hash  string `json:"hash"`
 				} `json:"part_set_header"`
 			} `json:"block_id"`
-			Signatures []struct {
-				BlockIDFlag      string    `json:"block_id_flag"`
-				ValidatorAddress string    `json:"validator_address"`
-				Timestamp        time.Time `json:"timestamp"`
-				Signature        string    `json:"signature"`
-			} `json:"signatures"`
+			Signatures []CommitSignature `json:"signatures"`
 		} `json:"last_commit"`
 	} `json:"block"`
 }
 
+// CommitSignature is the signature of a validator in the commit of a block. Signatures are in the order of the
+// validator set, absent signatures have no validator address
+type CommitSignature struct {
+	BlockIDFlag      string    `json:"block_id_flag"`
+	ValidatorAddress string    `json:"validator_address"`
+	Timestamp        time.Time `json:"timestamp"`
+	Signature        string    `json:"signature"`
+}
+
 func (b *BlockResponse) GetLatestBlock(endpoint string, client *http.Client) error {
 	var body []byte
 
@@ -201,29 +208,127 @@ func (b *BlockResponse) GetLatestBlock(endpoint string, client *http.Client) err
 	return nil
 }
 
-func (b *BlockResponse) GetBlock(height string, endpoint string) error {
-	url := endpoint + "/cosmos/base/tendermint/v1beta1/blocks/" + height
-	method := "GET"
+func (b *BlockResponse) GetBlock(height string, endpoint string, client *http.Client) error {
+	var body []byte
 
-	client := &http.Client{}
-	req, err := http.NewRequest(method, url, nil)
+	url := endpoint + "/cosmos/base/tendermint/v1beta1/blocks/" + height
+	body, err := HttpGet(url, client)
 	if err != nil {
+		// the body explains why a height is not available (e.g. pruned)
+		return errors.New(fmt.Sprintf("%s: %s", err, string(body)))
+	}
+
+	if err = json.Unmarshal(body, b); err != nil {
 		return err
 	}
+	return nil
+}
 
-	res, err := client.Do(req)
-	if err != nil {
+// ValidatorSetPageLimit is the number of validators requested per page of the validator set, CometBFT returns
+// no more than 100
+const ValidatorSetPageLimit = 100
+
+// QueryValidatorSet queries the validator set at the given height, in the order of the commit signatures. The
+// validator set has no next_key, it is paged by offset until its total is reached
+func (v *ValidatorSet) QueryValidatorSet(height string, endpoint string, client *http.Client) error {
+	url := endpoint + "/cosmos/base/tendermint/v1beta1/validatorsets/" + height
+	for {
+		body, err := HttpGet(fmt.Sprintf("%s?pagination.offset=%d&pagination.limit=%d", url, len(v.Validators), ValidatorSetPageLimit), client)
+		if err != nil {
+			return err
+		}
+
+		var page ValidatorSet
+		if err = json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+		v.BlockHeight = page.BlockHeight
+		v.Validators = append(v.Validators, page.Validators...)
+		v.Pagination = page.Pagination
+		if lastValidatorSetPage(len(page.Validators), len(v.Validators), page.Pagination.Total) {
+			return nil
+		}
+	}
+}
+
+// lastValidatorSetPage returns true when no validator is left to page after a page of count validators, paged
+// validators in total. Nodes not returning the total of the set end it with a page that is not full
+func lastValidatorSetPage(count int, paged int, total string) bool {
+	if count == 0 {
+		return true
+	}
+	n, err := strconv.Atoi(total)
+	if err != nil || n == 0 {
+		return count < ValidatorSetPageLimit
+	}
+	return paged >= n
+}
+
+// GetBlockAt finds the last block produced at or before the given time,
+// using a binary search over the heights available on the node
+func (b *BlockResponse) GetBlockAt(at time.Time, querier Querier) error {
+	latest := BlockResponse{}
+	if err := querier.LatestBlock(&latest); err != nil {
 		return err
 	}
-	defer res.Body.Close()
 
-	body, err := io.ReadAll(res.Body)
+	if !latest.Block.Header.Time.After(at) {
+		*b = latest
+		return nil
+	}
+
+	hi, err := strconv.ParseInt(latest.Block.Header.Height, 10, 64)
 	if err != nil {
 		return err
 	}
-	err = json.Unmarshal(body, b)
+
+	lo, lowest, err := earliestBlock(querier)
 	if err != nil {
 		return err
 	}
+
+	if lowest.Block.Header.Time.After(at) {
+		return errors.New(fmt.Sprintf("%s is before the earliest available block %d (%s)", at.Format(time.RFC3339), lo, lowest.Block.Header.Time.Format(time.RFC3339)))
+	}
+
+	// the block at lo is at or before the requested time and the block at hi is after it
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		block := BlockResponse{}
+		if err = querier.Block(strconv.FormatInt(mid, 10), &block); err != nil {
+			return err
+		}
+
+		if block.Block.Header.Time.After(at) {
+			hi = mid
+		} else {
+			lo = mid
+			lowest = block
+		}
+	}
+
+	*b = lowest
 	return nil
 }
+
+var lowestHeightRegex = regexp.MustCompile(`lowest height is (\d+)`)
+
+// earliestBlock returns the first block available on the node, which is not the first block of the chain on pruned nodes
+func earliestBlock(querier Querier) (int64, BlockResponse, error) {
+	block := BlockResponse{}
+	err := querier.Block("1", &block)
+	if err == nil {
+		return 1, block, nil
+	}
+
+	match := lowestHeightRegex.FindStringSubmatch(err.Error())
+	if match == nil {
+		return 0, block, err
+	}
+
+	height, _ := strconv.ParseInt(match[1], 10, 64)
+	if err = querier.Block(match[1], &block); err != nil {
+		return 0, block, err
+	}
+	return height, block, nil
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var genesisTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// blockTime is the time of the block at height, blocks are produced every 6 seconds
+func blockTime(height int64) time.Time {
+	return genesisTime.Add(time.Duration(height-1) * 6 * time.Second)
+}
+
+// blockQuerier serves the blocks from lowest to latest, like a node pruned below lowest. Queries of the other
+// methods panic
+type blockQuerier struct {
+	Querier
+	lowest int64
+	latest int64
+	// pruned is the error of a block below lowest, the lowest height is appended to it
+	pruned  string
+	queried []int64
+}
+
+func (q *blockQuerier) LatestBlock(resp *BlockResponse) error {
+	return q.Block(strconv.FormatInt(q.latest, 10), resp)
+}
+
+func (q *blockQuerier) Block(height string, resp *BlockResponse) error {
+	h, err := strconv.ParseInt(height, 10, 64)
+	if err != nil {
+		return err
+	}
+	q.queried = append(q.queried, h)
+
+	if h < q.lowest {
+		return errors.New(fmt.Sprintf("%s%d", q.pruned, q.lowest))
+	}
+	if h > q.latest {
+		return errors.New(fmt.Sprintf("height %d must be less than or equal to the current blockchain height %d", h, q.latest))
+	}
+	*resp = BlockResponse{}
+	resp.Block.Header.Height = height
+	resp.Block.Header.Time = blockTime(h)
+	return nil
+}
+
+const prunedMessage = `status code 400: {"code":3,"message":"height 1 is not available, lowest height is `
+
+func TestGetBlockAt(t *testing.T) {
+	tests := []struct {
+		name   string
+		lowest int64
+		at     time.Time
+		want   int64
+		err    string
+	}{
+		{"exactly on a block", 1, blockTime(4321), 4321, ""},
+		{"between blocks", 1, blockTime(4321).Add(5 * time.Second), 4321, ""},
+		{"on the first block", 1, blockTime(1), 1, ""},
+		{"on the latest block", 1, blockTime(10000), 10000, ""},
+		{"after the latest block", 1, blockTime(10000).Add(time.Hour), 10000, ""},
+		{"before the first block", 1, blockTime(1).Add(-time.Second), 0, "before the earliest available block 1"},
+		{"pruned node", 5000, blockTime(7777), 7777, ""},
+		{"on the lowest block of a pruned node", 5000, blockTime(5000), 5000, ""},
+		{"before the lowest block of a pruned node", 5000, blockTime(4999), 0, "before the earliest available block 5000"},
+	}
+	for _, test := range tests {
+		querier := &blockQuerier{lowest: test.lowest, latest: 10000, pruned: prunedMessage}
+
+		block := BlockResponse{}
+		err := block.GetBlockAt(test.at, querier)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%s: err = %v, want %s", test.name, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if block.Block.Header.Height != strconv.FormatInt(test.want, 10) {
+			t.Errorf("%s: block %s, want %d", test.name, block.Block.Header.Height, test.want)
+		}
+		// a binary search over 10000 blocks
+		if len(querier.queried) > 20 {
+			t.Errorf("%s: %d blocks queried, want a binary search", test.name, len(querier.queried))
+		}
+	}
+}
+
+func TestEarliestBlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		lowest  int64
+		pruned  string
+		want    int64
+		queried int
+		err     bool
+	}{
+		{"archive node", 1, prunedMessage, 1, 1, false},
+		{"pruned node", 5000, prunedMessage, 5000, 2, false},
+		{"pruned node over grpc", 5000, "rpc error: code = InvalidArgument desc = height 1 is not available, lowest height is ", 5000, 2, false},
+		{"unknown error", 5000, "connection refused ", 0, 1, true},
+	}
+	for _, test := range tests {
+		querier := &blockQuerier{lowest: test.lowest, latest: 10000, pruned: test.pruned}
+
+		height, block, err := earliestBlock(querier)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: earliest block %d, want an error", test.name, height)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if height != test.want || block.Block.Header.Height != strconv.FormatInt(test.want, 10) || !block.Block.Header.Time.Equal(blockTime(test.want)) {
+			t.Errorf("%s: earliest block = %d (%s at %s), want %d", test.name, height, block.Block.Header.Height, block.Block.Header.Time, test.want)
+		}
+		if len(querier.queried) != test.queried {
+			t.Errorf("%s: %d blocks queried, want %d", test.name, len(querier.queried), test.queried)
+		}
+	}
+}
@@ -1,30 +1,143 @@
 package model
 
-import "time"
+import (
+	"math/big"
+	"sort"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	"github.com/informalsystems/stakooler/client/cosmos/api"
+)
 
 type Account struct {
-	Name        string
-	Address     string
-	Valoper     string
-	BlockTime   time.Time
-	BlockHeight string
-	Tokens      map[string]*Token
-	TotalUSD    float64
-	TotalCAD    float64
+	Name    string
+	Address string
+	Valoper string
+	// WithdrawAddress receives the rewards and commissions of the account when they are withdrawn
+	WithdrawAddress string
+	BlockTime       time.Time
+	BlockHeight     string
+	Tokens          map[string]*Token
+	// Totals holds the value of the account per quote currency
+	Totals map[string]float64
+	// Delegations breaks the staking balances down per validator
+	Delegations []*Delegation
+	// Unbondings lists the unbonding entries ordered by completion time
+	Unbondings []*UnbondingEntry
+	// Redelegations lists the redelegation entries in flight ordered by completion time
+	Redelegations []*RedelegationEntry
+	// Vesting is the schedule of vesting accounts, nil for other accounts
+	Vesting *VestingSchedule
 }
 
+// Token holds the balances of a denom in base units, Exponent is used to convert them to display units
 type Token struct {
 	DisplayName string
 	Denom       string
-	PriceUSD    float64
-	PriceCAD    float64
-	Balances    struct {
-		Bank             float64
-		Rewards          float64
-		Commission       float64
-		Delegated        float64
-		Unbonding        float64
-		OriginalVesting  float64
-		DelegatedVesting float64
+	Exponent    int
+	// BaseDenom, SourceChain and IbcPath describe the origin of IBC tokens
+	BaseDenom   string
+	SourceChain string
+	IbcPath     string
+	// Prices holds the price of one display unit per quote currency
+	Prices   map[string]api.Price
+	Balances Balances
+}
+
+// Balances follow the accounting of the chain: Bank holds every token of the account, including tokens
+// still locked by vesting, and Delegated includes the delegated vesting tokens
+type Balances struct {
+	Bank sdkmath.Int
+	// Spendable and Locked split Bank into the tokens that can be sent and the ones locked by vesting
+	Spendable  sdkmath.Int
+	Locked     sdkmath.Int
+	Rewards    sdkmath.Int
+	Commission sdkmath.Int
+	Delegated  sdkmath.Int
+	Unbonding  sdkmath.Int
+	// Redelegating is the part of Delegated being redelegated. It is not a balance of its own, the tokens are
+	// delegated to the destination validator as soon as the redelegation starts
+	Redelegating sdkmath.Int
+	// OriginalVesting, DelegatedFree and DelegatedVesting are tracked by vesting accounts. They are not
+	// balances of their own: DelegatedFree and DelegatedVesting split the delegated tokens
+	OriginalVesting  sdkmath.Int
+	DelegatedFree    sdkmath.Int
+	DelegatedVesting sdkmath.Int
+}
+
+func NewToken(displayName string, denom string, exponent int) *Token {
+	return &Token{
+		DisplayName: displayName,
+		Denom:       denom,
+		Exponent:    exponent,
+		Prices:      make(map[string]api.Price),
+		Balances: Balances{
+			Bank:             sdkmath.ZeroInt(),
+			Spendable:        sdkmath.ZeroInt(),
+			Locked:           sdkmath.ZeroInt(),
+			Rewards:          sdkmath.ZeroInt(),
+			Commission:       sdkmath.ZeroInt(),
+			Delegated:        sdkmath.ZeroInt(),
+			Unbonding:        sdkmath.ZeroInt(),
+			Redelegating:     sdkmath.ZeroInt(),
+			OriginalVesting:  sdkmath.ZeroInt(),
+			DelegatedFree:    sdkmath.ZeroInt(),
+			DelegatedVesting: sdkmath.ZeroInt(),
+		},
 	}
 }
+
+// SortedTokens returns the account tokens ordered by display name and denom, so output does not depend on map ordering
+func (a *Account) SortedTokens() []*Token {
+	tokens := make([]*Token, 0, len(a.Tokens))
+	for _, token := range a.Tokens {
+		tokens = append(tokens, token)
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		if tokens[i].DisplayName != tokens[j].DisplayName {
+			return tokens[i].DisplayName < tokens[j].DisplayName
+		}
+		return tokens[i].Denom < tokens[j].Denom
+	})
+	return tokens
+}
+
+// Total returns every token owned by the account: bank (spendable and locked), delegated, unbonding,
+// pending rewards and commissions. Redelegating tokens are counted with the delegated ones
+func (t *Token) Total() sdkmath.Int {
+	return t.Balances.Bank.
+		Add(t.Balances.Delegated).
+		Add(t.Balances.Unbonding).
+		Add(t.Balances.Rewards).
+		Add(t.Balances.Commission)
+}
+
+// updateTotals values the total of every token of the account per quote currency
+func (a *Account) updateTotals() {
+	for quote := range a.Totals {
+		delete(a.Totals, quote)
+	}
+	for _, token := range a.Tokens {
+		for quote, price := range token.Prices {
+			a.Totals[quote] += token.Value(token.Total(), price.Rate)
+		}
+	}
+}
+
+// Price returns the rate of one display unit in the quote currency, zero when unknown
+func (t *Token) Price(quote string) float64 {
+	return t.Prices[quote].Rate
+}
+
+// Value returns the value of an amount of base units of the token at the given price per display unit
+func (t *Token) Value(amount sdkmath.Int, price float64) float64 {
+	if amount.IsNil() || price == 0 {
+		return 0
+	}
+
+	converted := new(big.Float).SetInt(amount.BigInt())
+	converted.Quo(converted, new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(t.Exponent)), nil)))
+	value, _ := converted.Mul(converted, big.NewFloat(price)).Float64()
+	return value
+}
@@ -3,174 +3,348 @@ package model
 import (
 	"errors"
 	"fmt"
-	"math"
 	"net/http"
-	"strconv"
 	"strings"
 
+	sdkmath "cosmossdk.io/math"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
 	"github.com/informalsystems/stakooler/client/cosmos/api"
 	"github.com/rs/zerolog/log"
 )
 
-const zeroAmount = 0.00000
-
 type Chain struct {
 	Name         string
 	Id           string
 	RestEndpoint string
+	// Querier queries the chain over its configured transport, REST or gRPC
+	Querier      api.Querier
 	Bech32Prefix string
 	Accounts     []*Account
 	BondDenom    string
 	Exponent     int
 	AssetList    *api.AssetList
+	PriceOracle  *api.PriceOracle
+	// Directory finds the assets of other chains, e.g. the origin of IBC denoms
+	Directory   *api.AssetDirectory
+	DenomFilter DenomFilter
+	denoms      denomCache
+	validators  validatorCache
+}
+
+// Fresh returns a copy of the chain whose accounts have no balances yet, pricing tokens with oracle. The copy
+// shares the querier and the denoms already resolved, so a chain set up once can be fetched again while the
+// result of the previous fetch is still in use
+func (c *Chain) Fresh(oracle *api.PriceOracle) *Chain {
+	fresh := &Chain{
+		Name:         c.Name,
+		Id:           c.Id,
+		RestEndpoint: c.RestEndpoint,
+		Querier:      c.Querier,
+		Bech32Prefix: c.Bech32Prefix,
+		BondDenom:    c.BondDenom,
+		Exponent:     c.Exponent,
+		AssetList:    c.AssetList,
+		PriceOracle:  oracle,
+		Directory:    c.Directory,
+		DenomFilter:  c.DenomFilter,
+	}
+
+	c.denoms.mu.Lock()
+	fresh.denoms.entries = make(map[string]*denomEntry, len(c.denoms.entries))
+	for denom, entry := range c.denoms.entries {
+		fresh.denoms.entries[denom] = entry
+	}
+	c.denoms.mu.Unlock()
+
+	for _, acct := range c.Accounts {
+		fresh.Accounts = append(fresh.Accounts, &Account{
+			Name:    acct.Name,
+			Address: acct.Address,
+			Valoper: acct.Valoper,
+			Tokens:  make(map[string]*Token),
+			Totals:  make(map[string]float64),
+		})
+	}
+	return fresh
 }
 
-func (c *Chain) FetchAccountBalances(blockInfo api.BlockResponse, client *http.Client) error {
+// FetchAccountBalances queries the balances of every account of the chain with querier, e.g. c.Querier
+// pinned at the height of blockInfo
+func (c *Chain) FetchAccountBalances(blockInfo api.BlockResponse, querier api.Querier, client *http.Client) error {
 	for idx := range c.Accounts {
-		c.Accounts[idx].BlockTime = blockInfo.Block.Header.Time
-		c.Accounts[idx].BlockHeight = blockInfo.Block.Header.Height
+		if err := c.FetchAccountBalance(idx, blockInfo, querier, client); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-		acct := api.AcctResponse{}
-		if err := acct.QueryAuth(c.Accounts[idx].Address, c.RestEndpoint, client); err != nil {
-			if strings.Contains(err.Error(), "404") {
-				log.Error().Msg(fmt.Sprintf("account %s not found", c.Accounts[idx].Name))
-				continue
-			}
-			return errors.New(fmt.Sprintf("query account: %s", err))
-		} else {
-			if err = c.ParseAcctQueryResp(&acct, idx, client); err != nil {
-				return errors.New(fmt.Sprintf("process vesting: %s", err))
+// FetchAccountBalance queries the balances of the account at idx. Different accounts can be fetched concurrently
+func (c *Chain) FetchAccountBalance(idx int, blockInfo api.BlockResponse, querier api.Querier, client *http.Client) error {
+	c.Accounts[idx].BlockTime = blockInfo.Block.Header.Time
+	c.Accounts[idx].BlockHeight = blockInfo.Block.Header.Height
+
+	acct := api.AcctResponse{}
+	if err := querier.Account(c.Accounts[idx].Address, &acct); err != nil {
+		if strings.Contains(err.Error(), "404") {
+			log.Error().Msg(fmt.Sprintf("account %s not found", c.Accounts[idx].Name))
+			return nil
+		}
+		return errors.New(fmt.Sprintf("query account: %s", err))
+	} else {
+		if err = c.ParseAcctQueryResp(&acct, idx, client); err != nil {
+			return errors.New(fmt.Sprintf("process vesting: %s", err))
+		}
+		if c.Accounts[idx].Vesting, err = NewVestingSchedule(&acct); err != nil {
+			return errors.New(fmt.Sprintf("process vesting schedule: %s", err))
+		}
+		if c.Accounts[idx].Vesting != nil {
+			for denom := range c.Accounts[idx].Vesting.OriginalVesting {
+				c.Accounts[idx].Vesting.Denoms[denom] = c.DenomInfo(denom, client)
 			}
 		}
+	}
 
-		bank := &api.BankResponse{}
-		if err := bank.QueryBankBalances(c.Accounts[idx].Address, c.RestEndpoint, client); err != nil {
-			return errors.New(fmt.Sprintf("query bank balances: %s", err))
-		} else {
-			if err = c.ParseAcctQueryResp(bank, idx, client); err != nil {
-				return errors.New(fmt.Sprintf("process bank balances: %s", err))
-			}
+	bank := &api.BankResponse{}
+	if err := querier.BankBalances(c.Accounts[idx].Address, bank); err != nil {
+		return errors.New(fmt.Sprintf("query bank balances: %s", err))
+	} else {
+		if err = c.ParseAcctQueryResp(bank, idx, client); err != nil {
+			return errors.New(fmt.Sprintf("process bank balances: %s", err))
+		}
+	}
+
+	if err := c.splitBankBalances(idx, acct.IsVesting(), querier, client); err != nil {
+		return errors.New(fmt.Sprintf("process spendable balances: %s", err))
+	}
+
+	rewards := &api.RewardsResponse{}
+	if err := querier.Rewards(c.Accounts[idx].Address, rewards); err != nil {
+		return errors.New(fmt.Sprintf("query rewards: %s", err))
+	} else {
+		if err = c.ParseAcctQueryResp(rewards, idx, client); err != nil {
+			return errors.New(fmt.Sprintf("process rewards: %s", err))
 		}
+	}
 
-		rewards := &api.RewardsResponse{}
-		if err := rewards.QueryRewards(c.Accounts[idx].Address, c.RestEndpoint, client); err != nil {
-			return errors.New(fmt.Sprintf("query rewards: %s", err))
-		} else {
-			if err = c.ParseAcctQueryResp(rewards, idx, client); err != nil {
-				return errors.New(fmt.Sprintf("process rewards: %s", err))
-			}
+	withdraw := &api.WithdrawAddressResponse{}
+	if err := querier.WithdrawAddress(c.Accounts[idx].Address, withdraw); err != nil {
+		return errors.New(fmt.Sprintf("query withdraw address: %s", err))
+	}
+	c.Accounts[idx].WithdrawAddress = withdraw.WithdrawAddress
+
+	commission := &api.CommissionResponse{}
+	if err := querier.Commission(c.Accounts[idx].Valoper, commission); err != nil {
+		return errors.New(fmt.Sprintf("query commissions: %s", err))
+	} else if commission.Commissions.Commission != nil {
+		if err = c.ParseAcctQueryResp(commission, idx, client); err != nil {
+			return errors.New(fmt.Sprintf("process commissions: %s", err))
 		}
+	}
 
-		commission := &api.CommissionResponse{}
-		if err := commission.QueryCommission(c.Accounts[idx].Valoper, c.RestEndpoint, client); err != nil {
-			return errors.New(fmt.Sprintf("query commissions: %s", err))
-		} else if commission.Commissions.Commission != nil {
-			if err = c.ParseAcctQueryResp(commission, idx, client); err != nil {
-				return errors.New(fmt.Sprintf("process commissions: %s", err))
-			}
+	delegation := &api.Delegations{}
+	if err := querier.Delegations(c.Accounts[idx].Address, delegation); err != nil {
+		return errors.New(fmt.Sprintf("query delegations: %s", err))
+	} else {
+		if err = c.ParseAcctQueryResp(delegation, idx, client); err != nil {
+			return errors.New(fmt.Sprintf("process delegations: %s", err))
 		}
+	}
 
-		delegation := &api.Delegations{}
-		if err := delegation.QueryDelegations(c.Accounts[idx].Address, c.RestEndpoint, client); err != nil {
-			return errors.New(fmt.Sprintf("query delegations: %s", err))
-		} else {
-			if err = c.ParseAcctQueryResp(delegation, idx, client); err != nil {
-				return errors.New(fmt.Sprintf("process delegations: %s", err))
-			}
+	unbondings := &api.Unbondings{Denom: c.BondDenom}
+	if err := querier.Unbondings(c.Accounts[idx].Address, unbondings); err != nil {
+		return errors.New(fmt.Sprintf("query unbondings: %s", err))
+	} else {
+		if err = c.ParseAcctQueryResp(unbondings, idx, client); err != nil {
+			return errors.New(fmt.Sprintf("process unbondings: %s", err))
 		}
+	}
 
-		unbondings := &api.Unbondings{}
-		if err := unbondings.QueryUnbondings(c.Accounts[idx].Address, c.RestEndpoint, client); err != nil {
-			return errors.New(fmt.Sprintf("query unbondings: %s", err))
-		} else {
-			if err = c.ParseAcctQueryResp(unbondings, idx, client); err != nil {
-				return errors.New(fmt.Sprintf("process unbondings: %s", err))
-			}
+	redelegations := &api.Redelegations{Denom: c.BondDenom}
+	if err := querier.Redelegations(c.Accounts[idx].Address, redelegations); err != nil {
+		return errors.New(fmt.Sprintf("query redelegations: %s", err))
+	} else {
+		if err = c.ParseAcctQueryResp(redelegations, idx, client); err != nil {
+			return errors.New(fmt.Sprintf("process redelegations: %s", err))
 		}
 	}
+
+	delegations, err := c.collectDelegations(delegation, rewards, unbondings, blockInfo.Block.Header.Height, querier, client)
+	if err != nil {
+		return errors.New(fmt.Sprintf("process delegations per validator: %s", err))
+	}
+	c.Accounts[idx].Delegations = delegations
+
+	entries, err := c.collectUnbondings(unbondings, blockInfo.Block.Header.Height, querier, client)
+	if err != nil {
+		return errors.New(fmt.Sprintf("process unbonding entries: %s", err))
+	}
+	c.Accounts[idx].Unbondings = entries
+
+	redelegationEntries, err := c.collectRedelegations(redelegations, blockInfo.Block.Header.Height, querier, client)
+	if err != nil {
+		return errors.New(fmt.Sprintf("process redelegation entries: %s", err))
+	}
+	c.Accounts[idx].Redelegations = redelegationEntries
+	lockRedelegations(delegations, redelegationEntries)
+
+	c.Accounts[idx].updateTotals()
 	return nil
 }
 
-func (c *Chain) ParseAcctQueryResp(resp api.AccountQueryResponse, idx int, client *http.Client) error {
-	for balanceType, balance := range resp.GetBalances() {
-		for denom, amount := range balance {
-			if strings.HasPrefix(strings.ToUpper(denom), "GAMM/POOL/") ||
-				strings.HasPrefix(strings.ToUpper(denom), "IBC/") ||
-				strings.HasPrefix(strings.ToUpper(denom), "FACTORY/") ||
-				strings.HasPrefix(strings.ToUpper(denom), "ST") {
-				continue
-			}
+// HasAccount returns true when the address is one of the accounts of the chain
+func (c *Chain) HasAccount(address string) bool {
+	for _, acct := range c.Accounts {
+		if acct.Address == address {
+			return true
+		}
+	}
+	return false
+}
+
+// ExternalWithdrawAccounts returns the accounts withdrawing their rewards and commissions to an address that is
+// not an account of the chain, so the tokens withdrawn leave the report
+func (c *Chain) ExternalWithdrawAccounts() []*Account {
+	var external []*Account
+	for _, acct := range c.Accounts {
+		if acct.WithdrawAddress != "" && !c.HasAccount(acct.WithdrawAddress) {
+			external = append(external, acct)
+		}
+	}
+	return external
+}
 
-			symbol, exponent := GetDenomMetadata(denom, c, client)
-			floatAmount, err := strconv.ParseFloat(amount, 1)
-			if err != nil {
+// AddWithdrawAccounts adds the external withdraw addresses as accounts of the chain, named after the first account
+// withdrawing to them, and returns the index of the accounts added
+func (c *Chain) AddWithdrawAccounts() ([]int, error) {
+	var added []int
+	for _, acct := range c.ExternalWithdrawAccounts() {
+		if c.HasAccount(acct.WithdrawAddress) {
+			continue
+		}
+		_, decoded, err := bech32.DecodeAndConvert(acct.WithdrawAddress)
+		if err != nil {
+			return added, errors.New(fmt.Sprintf("cannot decode withdraw address %s: %s", acct.WithdrawAddress, err))
+		}
+		valoper, err := bech32.ConvertAndEncode(c.Bech32Prefix+"valoper", decoded)
+		if err != nil {
+			return added, err
+		}
+
+		c.Accounts = append(c.Accounts, &Account{
+			Name:    acct.Name + " withdraw",
+			Address: acct.WithdrawAddress,
+			Valoper: valoper,
+			Tokens:  make(map[string]*Token),
+			Totals:  make(map[string]float64),
+		})
+		added = append(added, len(c.Accounts)-1)
+	}
+	return added, nil
+}
+
+// splitBankBalances splits the bank balances of the account at idx into spendable and locked tokens. Vesting
+// accounts query their spendable balances, and fall back to their vesting schedule if the query is not supported
+func (c *Chain) splitBankBalances(idx int, vesting bool, querier api.Querier, client *http.Client) error {
+	acct := c.Accounts[idx]
+	if vesting {
+		spendable := &api.SpendableResponse{}
+		err := querier.SpendableBalances(acct.Address, spendable)
+		if err == nil {
+			if err = c.ParseAcctQueryResp(spendable, idx, client); err != nil {
 				return err
 			}
+			for _, token := range acct.Tokens {
+				token.Balances.Locked = token.Balances.Bank.Sub(token.Balances.Spendable)
+			}
+			return nil
+		}
+		log.Debug().Err(err).Msg(fmt.Sprintf("cannot query spendable balances of %s on %s, using the vesting schedule", acct.Name, c.Id))
+	}
 
-			if floatAmount > zeroAmount {
-				convertedAmmount := floatAmount / math.Pow10(exponent)
-
-				if _, ok := c.Accounts[idx].Tokens[denom]; !ok {
-					USDprice := api.AssetPair{
-						AssetIdBase:  symbol,
-						AssetIdQuote: "USD",
-						Rate:         0,
-					}
+	for denom, token := range acct.Tokens {
+		// the chain only locks the vesting tokens that are not delegated
+		locked := sdkmath.ZeroInt()
+		if acct.Vesting != nil {
+			locked = acct.Vesting.Locked(denom, acct.BlockTime).Sub(token.Balances.DelegatedVesting)
+		}
+		locked = sdkmath.MaxInt(sdkmath.ZeroInt(), sdkmath.MinInt(locked, token.Balances.Bank))
+		token.Balances.Locked = locked
+		token.Balances.Spendable = token.Balances.Bank.Sub(locked)
+	}
+	return nil
+}
 
-					err2 := USDprice.GetCoinApiQuote()
-					if err2 != nil {
-						err2 = USDprice.GetCoinGekoQuote()
-						if err2 != nil {
-							log.Error().Err(err).Msg(fmt.Sprintf("failed fetching price for %s", symbol))
-						}
-					}
+func (c *Chain) ParseAcctQueryResp(resp api.AccountQueryResponse, idx int, client *http.Client) error {
+	balances, err := resp.GetBalances()
+	if err != nil {
+		return err
+	}
 
-					CADprice := api.AssetPair{
-						AssetIdBase:  symbol,
-						AssetIdQuote: "CAD",
-					}
+	for balanceType, balance := range balances {
+		for denom, amount := range balance {
+			// excluded denoms are dropped before being resolved, e.g. to skip lots of pool shares
+			if !amount.IsPositive() || c.DenomFilter.Excludes(denom) {
+				continue
+			}
 
-					err3 := CADprice.GetCoinApiQuote()
-					if err3 != nil {
-						err3 = CADprice.GetCoinGekoQuote()
-						if err3 != nil {
-							log.Error().Err(err3).Msg(fmt.Sprintf("failed fetching price for %s", symbol))
-						}
-					}
+			if _, ok := c.Accounts[idx].Tokens[denom]; !ok {
+				info := c.DenomInfo(denom, client)
+				if !c.DenomFilter.Allows(denom, info.BaseDenom, info.Symbol) {
+					continue
+				}
 
-					c.Accounts[idx].Tokens[denom] = &Token{
-						DisplayName: symbol,
-						Denom:       denom,
-						PriceCAD:    CADprice.Rate,
-						PriceUSD:    USDprice.Rate,
+				token := NewToken(info.DisplayName, denom, info.Exponent)
+				token.BaseDenom = info.BaseDenom
+				token.SourceChain = info.SourceChain
+				token.IbcPath = info.IbcPath
+				if c.PriceOracle != nil && info.Symbol != "" {
+					for _, quote := range c.PriceOracle.Currencies {
+						token.Prices[quote] = c.getPrice(info.Symbol, quote)
 					}
 				}
+				c.Accounts[idx].Tokens[denom] = token
+			}
 
-				c.Accounts[idx].TotalCAD += convertedAmmount * c.Accounts[idx].Tokens[denom].PriceCAD
-				c.Accounts[idx].TotalUSD += convertedAmmount * c.Accounts[idx].Tokens[denom].PriceUSD
-
-				switch balanceType {
-				case api.OriginalVesting:
-					c.Accounts[idx].Tokens[denom].Balances.OriginalVesting += convertedAmmount
-				case api.DelegatedVesting:
-					c.Accounts[idx].Tokens[denom].Balances.DelegatedVesting += convertedAmmount
-				case api.Bank:
-					c.Accounts[idx].Tokens[denom].Balances.Bank += convertedAmmount
-				case api.Rewards:
-					c.Accounts[idx].Tokens[denom].Balances.Rewards += convertedAmmount
-				case api.Commission:
-					c.Accounts[idx].Tokens[denom].Balances.Commission += convertedAmmount
-				case api.Delegation:
-					c.Accounts[idx].Tokens[denom].Balances.Delegated += convertedAmmount
-				case api.Unbonding:
-					c.Accounts[idx].Tokens[denom].Balances.Unbonding += convertedAmmount
-				}
+			token := c.Accounts[idx].Tokens[denom]
+			switch balanceType {
+			case api.OriginalVesting:
+				token.Balances.OriginalVesting = token.Balances.OriginalVesting.Add(amount)
+			case api.DelegatedFree:
+				token.Balances.DelegatedFree = token.Balances.DelegatedFree.Add(amount)
+			case api.DelegatedVesting:
+				token.Balances.DelegatedVesting = token.Balances.DelegatedVesting.Add(amount)
+			case api.Bank:
+				token.Balances.Bank = token.Balances.Bank.Add(amount)
+			case api.Spendable:
+				token.Balances.Spendable = token.Balances.Spendable.Add(amount)
+			case api.Rewards:
+				token.Balances.Rewards = token.Balances.Rewards.Add(amount)
+			case api.Commission:
+				token.Balances.Commission = token.Balances.Commission.Add(amount)
+			case api.Delegation:
+				token.Balances.Delegated = token.Balances.Delegated.Add(amount)
+			case api.Unbonding:
+				token.Balances.Unbonding = token.Balances.Unbonding.Add(amount)
+			case api.Redelegation:
+				token.Balances.Redelegating = token.Balances.Redelegating.Add(amount)
 			}
 		}
 	}
 	return nil
 }
 
+// getPrice returns the price of the symbol from the chain's price oracle, or an empty price if it cannot be found.
+// Failed lookups are logged once by the oracle
+func (c *Chain) getPrice(symbol string, quote string) api.Price {
+	if c.PriceOracle == nil {
+		return api.Price{}
+	}
+
+	price, _ := c.PriceOracle.GetQuote(symbol, quote)
+	return price
+}
+
 // GetDenomMetadata checks if the provided denom is part of a chain's external asset list
 // and returns the UI friendly name and exponent
 func GetDenomMetadata(denom string, chain *Chain, client *http.Client) (string, int) {
@@ -183,7 +357,7 @@ func GetDenomMetadata(denom string, chain *Chain, client *http.Client) (string,
 	// in case asset details are missing
 	if exponent == 0 {
 		denomMetadata := &api.DenomMetadataResponse{}
-		if err := denomMetadata.QueryMetadataFromBank(denom, chain.RestEndpoint, client); err != nil {
+		if err := chain.Querier.DenomMetadata(denom, denomMetadata); err != nil {
 			log.Error().Err(err).Msg(fmt.Sprintf("getting metadata from bank for: %s", denom))
 		}
 		if denomMetadata.Metadata.Base == "" {
@@ -195,10 +369,5 @@ func GetDenomMetadata(denom string, chain *Chain, client *http.Client) (string,
 		}
 
 	}
-
-	// If it's an IBC denom add '(IBC)' to the symbol
-	if strings.HasPrefix(strings.ToUpper(denom), "IBC/") {
-		symbol = symbol + " (IBC)"
-	}
 	return symbol, exponent
 }
@@ -8,9 +8,54 @@ type RawAccountData struct {
 	} `json:"accounts"`
 
 	Chains []struct {
-		Name     string   `json:"name"`
-		Id       string   `json:"id"`
-		Rest     string   `json:"rest"`
+		Name string `json:"name"`
+		Id   string `json:"id"`
+		Rest string `json:"rest"`
+		// RestEndpoints are more REST endpoints of the chain, queries fail over to them when rest is down
+		RestEndpoints []string `json:"rest_endpoints" mapstructure:"rest_endpoints"`
+		// RegistryRest also uses the public REST endpoints listed in the chain registry
+		RegistryRest bool `json:"registry_rest" mapstructure:"registry_rest"`
+		// Transport is how the chain is queried: rest (default) or grpc
+		Transport string `json:"transport"`
+		// Grpc is the gRPC endpoint (host:port) of the chain, queried when transport is grpc
+		Grpc string `json:"grpc"`
+		// GrpcTLS connects to the gRPC endpoint over TLS
+		GrpcTLS  bool     `json:"grpc_tls" mapstructure:"grpc_tls"`
 		Accounts []string `json:"accounts"`
+		// Denoms filters the denoms of the chain, on top of the global filter
+		Denoms RawDenomFilter `json:"denoms"`
 	} `json:"chains"`
+
+	// Denoms filters the denoms of every chain
+	Denoms RawDenomFilter `json:"denoms"`
+
+	Prices struct {
+		// Providers lists the price providers (coinapi, coingecko or file) in the order they are queried
+		Providers []string `json:"providers"`
+		// File is the path of a json or csv price file used by the file provider
+		File string `json:"file"`
+		// Currencies are the quote currencies prices and totals are reported in
+		Currencies []string `json:"currencies"`
+		Cache      struct {
+			// File persists looked up prices between runs when set
+			File string `json:"file"`
+			// TTL is how long persisted prices are reused (e.g. 1h), prices are bucketed by this duration
+			TTL string `json:"ttl"`
+		} `json:"cache"`
+	} `json:"prices"`
+
+	Zabbix struct {
+		// Server is the address of the Zabbix server or proxy trapper
+		Server string `json:"server"`
+		// Port of the trapper, 10051 when not set
+		Port int `json:"port"`
+		// Host is the name of the Zabbix host the items belong to
+		Host string `json:"host"`
+	} `json:"zabbix"`
+}
+
+// RawDenomFilter lists glob patterns (e.g. ibc/*, factory/*) of denoms to include or exclude
+type RawDenomFilter struct {
+	Include []string `json:"include"`
+	Exclude []string `json:"exclude"`
 }
@@ -0,0 +1,336 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	"github.com/informalsystems/stakooler/client/cosmos/api"
+	"github.com/rs/zerolog/log"
+)
+
+// validator statuses
+const (
+	StatusBonded    = "bonded"
+	StatusUnbonding = "unbonding"
+	StatusUnbonded  = "unbonded"
+)
+
+// Delegation is the stake of an account with a single validator. Amounts are in base units of Denom
+type Delegation struct {
+	ValidatorAddress string
+	Moniker          string
+	Status           string
+	Jailed           bool
+	// CommissionRate is the validator commission in percent
+	CommissionRate float64
+	Denom          string
+	DisplayName    string
+	Exponent       int
+	Shares         sdkmath.LegacyDec
+	Amount         sdkmath.Int
+	// Rewards are the pending rewards in Denom, rewards in other denoms are only part of the token balances
+	Rewards   sdkmath.Int
+	Unbonding sdkmath.Int
+	// Redelegating is the part of Amount redelegated to the validator and still in flight. Until RedelegationLock
+	// no token can be redelegated from the validator, zero when it is not locked
+	Redelegating     sdkmath.Int
+	RedelegationLock time.Time
+}
+
+// UnbondingEntry is a single unbonding of an account from a validator, liquid at CompletionTime
+type UnbondingEntry struct {
+	ValidatorAddress string
+	Moniker          string
+	CreationHeight   int64
+	CompletionTime   time.Time
+	Denom            string
+	DisplayName      string
+	Exponent         int
+	InitialBalance   sdkmath.Int
+	Amount           sdkmath.Int
+}
+
+// RedelegationEntry is a single redelegation of an account, from SrcValidatorAddress to DstValidatorAddress,
+// in flight until CompletionTime. Until then the tokens can be slashed for misbehavior of the source validator
+// and nothing can be redelegated from the destination validator
+type RedelegationEntry struct {
+	SrcValidatorAddress string
+	SrcMoniker          string
+	DstValidatorAddress string
+	DstMoniker          string
+	CreationHeight      int64
+	CompletionTime      time.Time
+	Denom               string
+	DisplayName         string
+	Exponent            int
+	InitialBalance      sdkmath.Int
+	Amount              sdkmath.Int
+}
+
+// Active returns true when the validator is part of the active set and not jailed
+func (d *Delegation) Active() bool {
+	return d.Status == StatusBonded && !d.Jailed
+}
+
+// RedelegationLocked returns true when a redelegation to the validator is in flight at now
+func (d *Delegation) RedelegationLocked(now time.Time) bool {
+	return d.RedelegationLock.After(now)
+}
+
+// validatorCache keeps the validators of a chain looked up by the delegations of its accounts
+type validatorCache struct {
+	mu      sync.Mutex
+	entries map[string]*validatorEntry
+}
+
+type validatorEntry struct {
+	ready     chan struct{}
+	validator api.ValidatorInfo
+	err       error
+}
+
+// ValidatorInfo returns the validator with the given operator address as queried by querier, pinned at height
+// or querying the latest state when height is empty. Each validator is only queried once per height
+func (c *Chain) ValidatorInfo(valoper string, height string, querier api.Querier) (api.ValidatorInfo, error) {
+	key := valoper + "@" + height
+	c.validators.mu.Lock()
+	if c.validators.entries == nil {
+		c.validators.entries = make(map[string]*validatorEntry)
+	}
+	entry, ok := c.validators.entries[key]
+	if !ok {
+		entry = &validatorEntry{ready: make(chan struct{})}
+		c.validators.entries[key] = entry
+	}
+	c.validators.mu.Unlock()
+
+	if ok {
+		<-entry.ready
+		return entry.validator, entry.err
+	}
+
+	resp := &api.ValidatorResponse{}
+	entry.err = querier.Validator(valoper, resp)
+	entry.validator = resp.Validator
+	close(entry.ready)
+	return entry.validator, entry.err
+}
+
+// collectDelegations breaks the delegations, rewards and unbondings of an account down per validator,
+// ordered by delegated amount
+func (c *Chain) collectDelegations(delegations *api.Delegations, rewards *api.RewardsResponse, unbondings *api.Unbondings, height string, querier api.Querier, client *http.Client) ([]*Delegation, error) {
+	byValidator := make(map[string]*Delegation)
+	get := func(valoper string) *Delegation {
+		if _, ok := byValidator[valoper]; !ok {
+			byValidator[valoper] = &Delegation{
+				ValidatorAddress: valoper,
+				Denom:            c.BondDenom,
+				Shares:           sdkmath.LegacyZeroDec(),
+				Amount:           sdkmath.ZeroInt(),
+				Rewards:          sdkmath.ZeroInt(),
+				Unbonding:        sdkmath.ZeroInt(),
+				Redelegating:     sdkmath.ZeroInt(),
+			}
+		}
+		return byValidator[valoper]
+	}
+
+	for _, response := range delegations.DelegationResponses {
+		delegation := get(response.Delegation.ValidatorAddress)
+		amount, ok := sdkmath.NewIntFromString(response.Balance.Amount)
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("cannot parse delegation amount %s", response.Balance.Amount))
+		}
+		shares, err := sdkmath.LegacyNewDecFromStr(response.Delegation.Shares)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("cannot parse delegation shares %s: %s", response.Delegation.Shares, err))
+		}
+		delegation.Denom = response.Balance.Denom
+		delegation.Amount = delegation.Amount.Add(amount)
+		delegation.Shares = delegation.Shares.Add(shares)
+	}
+
+	for _, response := range rewards.Rewards {
+		for _, reward := range response.Reward {
+			if reward.Denom != c.BondDenom {
+				continue
+			}
+			// rewards are decimals, only whole base units can be withdrawn
+			amount, err := sdkmath.LegacyNewDecFromStr(reward.Amount)
+			if err != nil {
+				return nil, errors.New(fmt.Sprintf("cannot parse reward amount %s: %s", reward.Amount, err))
+			}
+			delegation := get(response.ValidatorAddress)
+			delegation.Rewards = delegation.Rewards.Add(amount.TruncateInt())
+		}
+	}
+
+	for _, response := range unbondings.UnbondingResponses {
+		delegation := get(response.ValidatorAddress)
+		for _, entry := range response.Entries {
+			amount, ok := sdkmath.NewIntFromString(entry.Balance)
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("cannot parse unbonding amount %s", entry.Balance))
+			}
+			delegation.Unbonding = delegation.Unbonding.Add(amount)
+		}
+	}
+
+	result := make([]*Delegation, 0, len(byValidator))
+	for valoper, delegation := range byValidator {
+		info := c.DenomInfo(delegation.Denom, client)
+		delegation.DisplayName, delegation.Exponent = info.DisplayName, info.Exponent
+
+		validator, err := c.ValidatorInfo(valoper, height, querier)
+		if err != nil {
+			log.Error().Err(err).Msg(fmt.Sprintf("cannot query validator %s on %s", valoper, c.Id))
+		} else {
+			delegation.Moniker = validator.Description.Moniker
+			delegation.Jailed = validator.Jailed
+			delegation.Status = validatorStatus(validator.Status)
+			if rate, err := strconv.ParseFloat(validator.Commission.CommissionRates.Rate, 64); err == nil {
+				delegation.CommissionRate = rate * 100.0
+			}
+		}
+		result = append(result, delegation)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Amount.Equal(result[j].Amount) {
+			return result[i].Amount.GT(result[j].Amount)
+		}
+		return result[i].ValidatorAddress < result[j].ValidatorAddress
+	})
+	return result, nil
+}
+
+// collectUnbondings lists the unbonding entries of an account, ordered by completion time
+func (c *Chain) collectUnbondings(unbondings *api.Unbondings, height string, querier api.Querier, client *http.Client) ([]*UnbondingEntry, error) {
+	info := c.DenomInfo(unbondings.Denom, client)
+
+	var entries []*UnbondingEntry
+	for _, response := range unbondings.UnbondingResponses {
+		moniker := ""
+		if validator, err := c.ValidatorInfo(response.ValidatorAddress, height, querier); err == nil {
+			moniker = validator.Description.Moniker
+		}
+
+		for _, entry := range response.Entries {
+			amount, ok := sdkmath.NewIntFromString(entry.Balance)
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("cannot parse unbonding amount %s", entry.Balance))
+			}
+			initial, ok := sdkmath.NewIntFromString(entry.InitialBalance)
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("cannot parse unbonding initial balance %s", entry.InitialBalance))
+			}
+			height, err := strconv.ParseInt(entry.CreationHeight, 10, 64)
+			if err != nil {
+				return nil, errors.New(fmt.Sprintf("cannot parse unbonding creation height %s: %s", entry.CreationHeight, err))
+			}
+
+			entries = append(entries, &UnbondingEntry{
+				ValidatorAddress: response.ValidatorAddress,
+				Moniker:          moniker,
+				CreationHeight:   height,
+				CompletionTime:   entry.CompletionTime,
+				Denom:            unbondings.Denom,
+				DisplayName:      info.DisplayName,
+				Exponent:         info.Exponent,
+				InitialBalance:   initial,
+				Amount:           amount,
+			})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CompletionTime.Before(entries[j].CompletionTime)
+	})
+	return entries, nil
+}
+
+// collectRedelegations lists the redelegation entries of an account, ordered by completion time
+func (c *Chain) collectRedelegations(redelegations *api.Redelegations, height string, querier api.Querier, client *http.Client) ([]*RedelegationEntry, error) {
+	info := c.DenomInfo(redelegations.Denom, client)
+	moniker := func(valoper string) string {
+		if validator, err := c.ValidatorInfo(valoper, height, querier); err == nil {
+			return validator.Description.Moniker
+		}
+		return ""
+	}
+
+	var entries []*RedelegationEntry
+	for _, response := range redelegations.RedelegationResponses {
+		src, dst := response.Redelegation.ValidatorSrcAddress, response.Redelegation.ValidatorDstAddress
+		for _, entry := range response.Entries {
+			amount, ok := sdkmath.NewIntFromString(entry.Balance)
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("cannot parse redelegation amount %s", entry.Balance))
+			}
+			initial, ok := sdkmath.NewIntFromString(entry.RedelegationEntry.InitialBalance)
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("cannot parse redelegation initial balance %s", entry.RedelegationEntry.InitialBalance))
+			}
+			height, err := strconv.ParseInt(entry.RedelegationEntry.CreationHeight, 10, 64)
+			if err != nil {
+				return nil, errors.New(fmt.Sprintf("cannot parse redelegation creation height %s: %s", entry.RedelegationEntry.CreationHeight, err))
+			}
+
+			entries = append(entries, &RedelegationEntry{
+				SrcValidatorAddress: src,
+				SrcMoniker:          moniker(src),
+				DstValidatorAddress: dst,
+				DstMoniker:          moniker(dst),
+				CreationHeight:      height,
+				CompletionTime:      entry.RedelegationEntry.CompletionTime,
+				Denom:               redelegations.Denom,
+				DisplayName:         info.DisplayName,
+				Exponent:            info.Exponent,
+				InitialBalance:      initial,
+				Amount:              amount,
+			})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CompletionTime.Before(entries[j].CompletionTime)
+	})
+	return entries, nil
+}
+
+// lockRedelegations sets the redelegations in flight to the validators of the delegations. The staking module
+// rejects redelegating from a validator while a redelegation to it is in flight, so the delegation is locked
+// until the last one completes
+func lockRedelegations(delegations []*Delegation, entries []*RedelegationEntry) {
+	for _, entry := range entries {
+		for _, delegation := range delegations {
+			if delegation.ValidatorAddress != entry.DstValidatorAddress {
+				continue
+			}
+			delegation.Redelegating = delegation.Redelegating.Add(entry.Amount)
+			if entry.CompletionTime.After(delegation.RedelegationLock) {
+				delegation.RedelegationLock = entry.CompletionTime
+			}
+		}
+	}
+}
+
+// validatorStatus converts a BOND_STATUS_* status to its short form
+func validatorStatus(status string) string {
+	switch status {
+	case "BOND_STATUS_BONDED":
+		return StatusBonded
+	case "BOND_STATUS_UNBONDING":
+		return StatusUnbonding
+	case "BOND_STATUS_UNBONDED":
+		return StatusUnbonded
+	}
+	return strings.ToLower(status)
+}
@@ -0,0 +1,180 @@
+package model
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/informalsystems/stakooler/client/cosmos/api"
+	"github.com/rs/zerolog/log"
+)
+
+// DenomFilter selects the denoms reported. Patterns are case-insensitive globs where * matches any sequence
+// of characters, including /, (e.g. ibc/*, gamm/pool/*) matched against the denom, and for IBC denoms also
+// against their base denom and symbol
+type DenomFilter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// NewDenomFilter returns a filter keeping only the denoms matching one of the include patterns, or every
+// denom when there are none, and dropping the ones matching an exclude pattern
+func NewDenomFilter(include []string, exclude []string) DenomFilter {
+	return DenomFilter{include: compileGlobs(include), exclude: compileGlobs(exclude)}
+}
+
+// Excludes returns true when one of the names matches an exclude pattern
+func (f DenomFilter) Excludes(names ...string) bool {
+	return matchAny(f.exclude, names)
+}
+
+// Allows returns true when the denom described by names is included and not excluded
+func (f DenomFilter) Allows(names ...string) bool {
+	if len(f.include) > 0 && !matchAny(f.include, names) {
+		return false
+	}
+	return !f.Excludes(names...)
+}
+
+func compileGlobs(patterns []string) []*regexp.Regexp {
+	var globs []*regexp.Regexp
+	for _, pattern := range patterns {
+		expr := regexp.QuoteMeta(pattern)
+		expr = strings.ReplaceAll(expr, `\*`, ".*")
+		expr = strings.ReplaceAll(expr, `\?`, ".")
+		globs = append(globs, regexp.MustCompile("(?i)^"+expr+"$"))
+	}
+	return globs
+}
+
+func matchAny(globs []*regexp.Regexp, names []string) bool {
+	for _, glob := range globs {
+		for _, name := range names {
+			if name != "" && glob.MatchString(name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DenomInfo describes how a denom is displayed and priced
+type DenomInfo struct {
+	// DisplayName is shown in reports, IBC denoms are suffixed with (IBC)
+	DisplayName string
+	// Symbol is the symbol prices are looked up with, empty when the denom cannot be priced
+	Symbol   string
+	Exponent int
+	// BaseDenom is the denom of an IBC token on the chain it was issued on
+	BaseDenom string
+	// SourceChain is the chain id an IBC token was received from
+	SourceChain string
+	// IbcPath is the port/channel path an IBC token travelled through
+	IbcPath string
+}
+
+// denomCache keeps the resolved denoms of a chain, so metadata and traces are only queried once
+// regardless of how many accounts hold a denom
+type denomCache struct {
+	mu      sync.Mutex
+	entries map[string]*denomEntry
+}
+
+type denomEntry struct {
+	ready chan struct{}
+	info  DenomInfo
+}
+
+// DenomInfo resolves the display name, symbol and exponent of a denom. IBC denoms are traced back
+// to their base denom and source chain and described with the asset of their origin
+func (c *Chain) DenomInfo(denom string, client *http.Client) DenomInfo {
+	c.denoms.mu.Lock()
+	if c.denoms.entries == nil {
+		c.denoms.entries = make(map[string]*denomEntry)
+	}
+	entry, ok := c.denoms.entries[denom]
+	if !ok {
+		entry = &denomEntry{ready: make(chan struct{})}
+		c.denoms.entries[denom] = entry
+	}
+	c.denoms.mu.Unlock()
+
+	if ok {
+		<-entry.ready
+		return entry.info
+	}
+
+	if strings.HasPrefix(strings.ToLower(denom), "ibc/") {
+		entry.info = c.ibcDenomInfo(denom, client)
+	} else {
+		symbol, exponent := GetDenomMetadata(denom, c, client)
+		entry.info = DenomInfo{DisplayName: symbol, Symbol: symbol, Exponent: exponent}
+	}
+	close(entry.ready)
+	return entry.info
+}
+
+func (c *Chain) ibcDenomInfo(denom string, client *http.Client) DenomInfo {
+	info := DenomInfo{}
+
+	trace := &api.DenomTraceResponse{}
+	if c.RestEndpoint == "" {
+		// IBC denoms are only traced over REST
+		log.Debug().Msg(fmt.Sprintf("no REST endpoint to trace %s on %s", denom, c.Id))
+	} else if err := trace.QueryDenomTrace(denom, c.RestEndpoint, client); err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("cannot trace %s on %s", denom, c.Id))
+	} else {
+		info.BaseDenom = trace.DenomTrace.BaseDenom
+		info.IbcPath = trace.DenomTrace.Path
+	}
+
+	hops := trace.Hops()
+	if len(hops) > 0 {
+		clientState := &api.ClientStateResponse{}
+		if err := clientState.QueryChannelClientState(hops[0].PortId, hops[0].ChannelId, c.RestEndpoint, client); err != nil {
+			log.Error().Err(err).Msg(fmt.Sprintf("cannot find the counterparty of %s/%s on %s", hops[0].PortId, hops[0].ChannelId, c.Id))
+		} else {
+			info.SourceChain = clientState.IdentifiedClientState.ClientState.ChainId
+		}
+	}
+
+	// the chain's own asset list usually knows the IBC assets it holds
+	if c.AssetList != nil {
+		info.Symbol, info.Exponent = c.AssetList.SearchForAsset(denom)
+	}
+
+	// otherwise the asset of the origin chain, only known when the token comes straight from it
+	if info.Exponent == 0 && len(hops) == 1 && info.SourceChain != "" && c.Directory != nil {
+		if list, err := c.Directory.AssetList(info.SourceChain); err != nil {
+			log.Debug().Err(err).Msg(fmt.Sprintf("no asset list for %s", info.SourceChain))
+		} else {
+			info.Symbol, info.Exponent = list.SearchForAsset(info.BaseDenom)
+		}
+	}
+
+	if info.Exponent == 0 {
+		denomMetadata := &api.DenomMetadataResponse{}
+		if err := c.Querier.DenomMetadata(denom, denomMetadata); err == nil && denomMetadata.Metadata.Base != "" {
+			info.Symbol = strings.ToUpper(denomMetadata.Metadata.Display)
+			info.Exponent = denomMetadata.GetExponent()
+		}
+	}
+
+	// in case asset details are missing the exponent is a guess, so the denom is shown but not priced
+	if info.Exponent == 0 {
+		name := denom
+		if info.BaseDenom != "" {
+			name = info.BaseDenom
+		}
+		log.Warn().Msg(fmt.Sprintf("no asset or metadata for %s (%s) on %s, assuming exponent 6 and leaving it unpriced", denom, name, c.Id))
+		info.Symbol = ""
+		info.Exponent = 6
+		info.DisplayName = name + " (IBC)"
+		return info
+	}
+
+	info.DisplayName = info.Symbol + " (IBC)"
+	return info
+}
@@ -0,0 +1,166 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	"github.com/informalsystems/stakooler/client/cosmos/api"
+)
+
+// GovProposal is a proposal in voting period with the votes of the configured accounts
+type GovProposal struct {
+	Chain         *Chain
+	Id            string
+	Title         string
+	VotingEndTime time.Time
+	Yes           sdkmath.Int
+	No            sdkmath.Int
+	Abstain       sdkmath.Int
+	NoWithVeto    sdkmath.Int
+	Votes         []*AccountVote
+}
+
+// AccountVote is the vote of a configured account on a proposal. Validators vote with the account of their
+// operator, which is the configured account
+type AccountVote struct {
+	Name  string
+	Voter string
+	Voted bool
+	// Options are the options voted with their weight, a single option with weight 1 for most votes
+	Options []api.WeightedVoteOption
+}
+
+// TotalVoted returns the tokens that voted so far
+func (p *GovProposal) TotalVoted() sdkmath.Int {
+	return p.Yes.Add(p.No).Add(p.Abstain).Add(p.NoWithVeto)
+}
+
+// Percent returns the share of the tokens that voted an option, e.g. p.Yes
+func (p *GovProposal) Percent(option sdkmath.Int) float64 {
+	total := p.TotalVoted()
+	if total.IsZero() {
+		return 0
+	}
+	return 100 * sdkmath.LegacyNewDecFromInt(option).QuoInt(total).MustFloat64()
+}
+
+// Missing returns the votes of the accounts that have not voted
+func (p *GovProposal) Missing() []*AccountVote {
+	var missing []*AccountVote
+	for _, vote := range p.Votes {
+		if !vote.Voted {
+			missing = append(missing, vote)
+		}
+	}
+	return missing
+}
+
+// EndsWithin returns true when the voting period ends in less than d from now
+func (p *GovProposal) EndsWithin(d time.Duration, now time.Time) bool {
+	return p.VotingEndTime.Sub(now) < d
+}
+
+// Option returns the options of the vote without their prefix, with their weight when the vote is split,
+// e.g. YES or YES 70% / NO 30%
+func (v *AccountVote) Option() string {
+	if !v.Voted {
+		return ""
+	}
+
+	var options []string
+	for _, option := range v.Options {
+		name := strings.TrimPrefix(option.Option, "VOTE_OPTION_")
+		if len(v.Options) == 1 {
+			options = append(options, name)
+			continue
+		}
+		weight, err := sdkmath.LegacyNewDecFromStr(option.Weight)
+		if err != nil {
+			options = append(options, name+" "+option.Weight)
+			continue
+		}
+		options = append(options, fmt.Sprintf("%s %s%%", name, weight.MulInt64(100).TruncateInt()))
+	}
+	return strings.Join(options, " / ")
+}
+
+// FetchGovProposals loads the proposals of the chain in voting period, ending first, with their current tally
+// and the votes of the configured accounts
+func (c *Chain) FetchGovProposals() ([]*GovProposal, error) {
+	resp := api.ProposalsResponse{}
+	if err := c.Querier.Proposals(api.ProposalStatusVotingPeriod, &resp); err != nil {
+		return nil, errors.New(fmt.Sprintf("query proposals: %s", err))
+	}
+	if len(resp.Proposals) == 0 {
+		return nil, nil
+	}
+
+	var proposals []*GovProposal
+	for _, proposal := range resp.Proposals {
+		tally := api.TallyResponse{}
+		if err := c.Querier.Tally(proposal.Id, &tally); err != nil {
+			return proposals, errors.New(fmt.Sprintf("query tally of proposal %s: %s", proposal.Id, err))
+		}
+
+		p := &GovProposal{Chain: c, Id: proposal.Id, Title: proposal.Title, VotingEndTime: proposal.VotingEndTime}
+		amounts := []string{tally.Tally.Yes, tally.Tally.No, tally.Tally.Abstain, tally.Tally.NoWithVeto}
+		parsed := make([]sdkmath.Int, len(amounts))
+		for i, amount := range amounts {
+			var err error
+			if parsed[i], err = parseTally(amount); err != nil {
+				return proposals, errors.New(fmt.Sprintf("tally of proposal %s: %s", proposal.Id, err))
+			}
+		}
+		p.Yes, p.No, p.Abstain, p.NoWithVeto = parsed[0], parsed[1], parsed[2], parsed[3]
+
+		for _, acct := range c.Accounts {
+			vote := &AccountVote{Name: acct.Name, Voter: acct.Address}
+			voteResp := api.VoteResponse{}
+			if err := c.Querier.Vote(proposal.Id, acct.Address, &voteResp); err != nil {
+				if !errors.Is(err, api.ErrNoVote) {
+					return proposals, errors.New(fmt.Sprintf("query vote of %s on proposal %s: %s", acct.Address, proposal.Id, err))
+				}
+			} else {
+				vote.Voted, vote.Options = true, voteResp.Vote.GetOptions()
+			}
+			p.Votes = append(p.Votes, vote)
+		}
+		proposals = append(proposals, p)
+	}
+
+	sort.SliceStable(proposals, func(i, j int) bool {
+		return proposals[i].VotingEndTime.Before(proposals[j].VotingEndTime)
+	})
+	return proposals, nil
+}
+
+// parseTally parses the tokens of a tally option, empty when no token voted the option
+func parseTally(amount string) (sdkmath.Int, error) {
+	if amount == "" {
+		return sdkmath.ZeroInt(), nil
+	}
+	parsed, ok := sdkmath.NewIntFromString(amount)
+	if !ok {
+		return sdkmath.ZeroInt(), errors.New(fmt.Sprintf("cannot parse tally %s", amount))
+	}
+	return parsed, nil
+}
+
+// FilterMissingVotes returns the proposals some accounts have not voted on, with only the missing votes
+func FilterMissingVotes(proposals []*GovProposal) []*GovProposal {
+	var filtered []*GovProposal
+	for _, proposal := range proposals {
+		missing := proposal.Missing()
+		if len(missing) == 0 {
+			continue
+		}
+		unvoted := *proposal
+		unvoted.Votes = missing
+		filtered = append(filtered, &unvoted)
+	}
+	return filtered
+}
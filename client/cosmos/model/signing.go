@@ -0,0 +1,225 @@
+package model
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/informalsystems/stakooler/client/cosmos/api"
+	"github.com/informalsystems/stakooler/client/pool"
+)
+
+// BlockIDFlagAbsent is the block id flag of the commit signature of a validator that missed the block. Validators
+// that signed for the block or for nil did not miss it
+const BlockIDFlagAbsent = "BLOCK_ID_FLAG_ABSENT"
+
+// SigningHistory is what a validator signed and proposed over a range of heights
+type SigningHistory struct {
+	Chain          *Chain
+	Moniker        string
+	ValoperAddress string
+	ConsAddress    string
+	FromHeight     int64
+	ToHeight       int64
+	Signed         int64
+	Missed         int64
+	Proposed       int64
+	// Streaks are the runs of consecutive missed blocks, in ascending height
+	Streaks []MissStreak
+}
+
+// MissStreak is a run of consecutive heights missed by a validator
+type MissStreak struct {
+	StartHeight int64
+	EndHeight   int64
+	StartTime   time.Time
+	EndTime     time.Time
+}
+
+// Length returns the number of blocks missed in a row
+func (s MissStreak) Length() int64 {
+	return s.EndHeight - s.StartHeight + 1
+}
+
+// Scanned returns the number of heights the validator was part of the validator set
+func (h *SigningHistory) Scanned() int64 {
+	return h.Signed + h.Missed
+}
+
+// SignedPercent returns the share of the scanned heights signed by the validator
+func (h *SigningHistory) SignedPercent() float64 {
+	if h.Scanned() == 0 {
+		return 0
+	}
+	return 100 * float64(h.Signed) / float64(h.Scanned())
+}
+
+// LongestStreak returns the longest run of missed blocks, the first one when there are several
+func (h *SigningHistory) LongestStreak() MissStreak {
+	var longest MissStreak
+	for _, streak := range h.Streaks {
+		if streak.Length() > longest.Length() {
+			longest = streak
+		}
+	}
+	return longest
+}
+
+// FetchSigningHistory scans the commit signatures of the last blocks heights for every account of the chain that is
+// a validator, querying up to workers blocks at the same time. The signatures of a height are read from the last
+// commit of the next block, so the heights scanned end one block before the latest one
+func (c *Chain) FetchSigningHistory(blocks int64, workers int, client *http.Client) ([]*SigningHistory, error) {
+	validators, err := c.accountValidators(client)
+	if err != nil {
+		return nil, err
+	}
+	if len(validators) == 0 {
+		return nil, nil
+	}
+
+	latest := api.BlockResponse{}
+	if err = c.Querier.LatestBlock(&latest); err != nil {
+		return nil, errors.New(fmt.Sprintf("query latest block: %s", err))
+	}
+	to, err := strconv.ParseInt(latest.Block.Header.Height, 10, 64)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid block height %s", latest.Block.Header.Height))
+	}
+	to--
+	from := to - blocks + 1
+	if from < 1 {
+		from = 1
+	}
+
+	// the blocks from..to and the next one, which holds the signatures of to
+	fetched := make([]api.BlockResponse, to-from+2)
+	errs := make([]error, len(fetched))
+	fetched[len(fetched)-1] = latest
+	pool.Run(len(fetched)-1, workers, func(i int) {
+		errs[i] = c.Querier.Block(strconv.FormatInt(from+int64(i), 10), &fetched[i])
+	})
+	for i, err := range errs {
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("query block %d: %s", from+int64(i), err))
+		}
+	}
+
+	histories := make([]*SigningHistory, len(validators))
+	for i, v := range validators {
+		histories[i] = &SigningHistory{
+			Chain:          c,
+			Moniker:        v.validator.Description.Moniker,
+			ValoperAddress: v.validator.OperatorAddress,
+			ConsAddress:    v.consBech32,
+			FromHeight:     from,
+			ToHeight:       to,
+		}
+	}
+
+	// the positions in the validator set by consensus address, per validator set hash. The set rarely changes
+	// between blocks, it is only queried again when its hash changes
+	positions := make(map[string]map[string]int)
+	for i := 0; i < len(fetched)-1; i++ {
+		height := from + int64(i)
+		block, commit := fetched[i], fetched[i+1].Block.LastCommit
+		if commit.Height != strconv.FormatInt(height, 10) {
+			return nil, errors.New(fmt.Sprintf("last commit of block %d is for height %s", height+1, commit.Height))
+		}
+
+		proposer, err := base64.StdEncoding.DecodeString(block.Block.Header.ProposerAddress)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("cannot decode proposer address of block %d: %s", height, err))
+		}
+
+		// the validator set is only queried when a validator may have missed the block
+		setHash := block.Block.Header.ValidatorsHash
+		if setHash == "" {
+			setHash = commit.Height
+		}
+		for j, v := range validators {
+			history := histories[j]
+			if bytes.Equal(proposer, v.consAddress) {
+				history.Proposed++
+			}
+
+			flag, found, err := signatureFlag(commit.Signatures, v.consAddress)
+			if err != nil {
+				return nil, errors.New(fmt.Sprintf("commit signatures of block %d: %s", height, err))
+			}
+			if !found && hasAbsentSignature(commit.Signatures) {
+				position, ok := positions[setHash]
+				if !ok {
+					set := api.ValidatorSet{}
+					if err = c.Querier.ValidatorSet(commit.Height, &set); err != nil {
+						return nil, errors.New(fmt.Sprintf("query validator set %d: %s", height, err))
+					}
+					position = make(map[string]int, len(set.Validators))
+					for k, validator := range set.Validators {
+						position[validator.Address] = k
+					}
+					positions[setHash] = position
+				}
+				if k, ok := position[v.consBech32]; ok && k < len(commit.Signatures) {
+					flag, found = commit.Signatures[k].BlockIDFlag, true
+				}
+			}
+			// validators outside of the active set do not sign
+			if !found {
+				continue
+			}
+
+			if flag != BlockIDFlagAbsent {
+				history.Signed++
+				continue
+			}
+
+			history.Missed++
+			last := len(history.Streaks) - 1
+			if last >= 0 && history.Streaks[last].EndHeight == height-1 {
+				history.Streaks[last].EndHeight = height
+				history.Streaks[last].EndTime = block.Block.Header.Time
+			} else {
+				history.Streaks = append(history.Streaks, MissStreak{
+					StartHeight: height,
+					EndHeight:   height,
+					StartTime:   block.Block.Header.Time,
+					EndTime:     block.Block.Header.Time,
+				})
+			}
+		}
+	}
+	return histories, nil
+}
+
+// signatureFlag returns the block id flag of the signature of the validator with the given consensus address.
+// found is false when no signature has the address, e.g. when the signature is absent
+func signatureFlag(signatures []api.CommitSignature, consAddress []byte) (string, bool, error) {
+	for _, signature := range signatures {
+		if signature.ValidatorAddress == "" {
+			continue
+		}
+		address, err := base64.StdEncoding.DecodeString(signature.ValidatorAddress)
+		if err != nil {
+			return "", false, errors.New(fmt.Sprintf("cannot decode validator address %s", signature.ValidatorAddress))
+		}
+		if bytes.Equal(address, consAddress) {
+			return signature.BlockIDFlag, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// hasAbsentSignature returns true when a validator of the set did not sign. Absent signatures have no address,
+// their validator is found by its position in the validator set, which is the position of the signature
+func hasAbsentSignature(signatures []api.CommitSignature) bool {
+	for _, signature := range signatures {
+		if signature.BlockIDFlag == BlockIDFlagAbsent {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package model
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	"github.com/informalsystems/stakooler/client/cosmos/api"
+	"github.com/rs/zerolog/log"
+)
+
+// Uptime is the liveness of a validator over the signed blocks window of its chain
+type Uptime struct {
+	Chain              *Chain
+	Moniker            string
+	ValoperAddress     string
+	ConsAddress        string
+	BlockTime          time.Time
+	BlockHeight        string
+	MissedBlocks       int64
+	SignedBlocksWindow int64
+	MinSignedPerWindow sdkmath.LegacyDec
+	Jailed             bool
+	JailedUntil        time.Time
+	Tombstoned         bool
+}
+
+// MaxMissedBlocks returns the number of blocks of the window the validator can miss before being jailed,
+// computed the way the slashing module does
+func (u *Uptime) MaxMissedBlocks() int64 {
+	minSigned := sdkmath.LegacyNewDec(u.SignedBlocksWindow).Mul(u.MinSignedPerWindow).RoundInt64()
+	return u.SignedBlocksWindow - minSigned
+}
+
+// Percent returns the share of the blocks of the window signed by the validator
+func (u *Uptime) Percent() float64 {
+	if u.SignedBlocksWindow == 0 {
+		return 0
+	}
+	return 100 * float64(u.SignedBlocksWindow-u.MissedBlocks) / float64(u.SignedBlocksWindow)
+}
+
+// AtRisk returns true when the validator is jailed, tombstoned or missed half of the blocks it can miss
+func (u *Uptime) AtRisk() bool {
+	return u.Jailed || u.Tombstoned || 2*u.MissedBlocks >= u.MaxMissedBlocks()
+}
+
+// WasJailed returns true when the validator has been jailed for downtime at least once, the slashing module
+// leaves jailed_until at the unix epoch otherwise
+func (u *Uptime) WasJailed() bool {
+	return u.JailedUntil.After(time.Unix(0, 0))
+}
+
+// ConsensusAddress returns the consensus address of a validator from the type and base64 key of its consensus
+// public key, as found in the commit signatures of blocks
+func ConsensusAddress(keyType string, key string) ([]byte, error) {
+	decoded, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("cannot decode consensus key %s: %s", key, err))
+	}
+
+	switch {
+	case strings.HasSuffix(keyType, "ed25519.PubKey"):
+		if len(decoded) != ed25519.PubKeySize {
+			return nil, errors.New(fmt.Sprintf("invalid ed25519 key length %d", len(decoded)))
+		}
+		return (&ed25519.PubKey{Key: decoded}).Address(), nil
+	case strings.HasSuffix(keyType, "secp256k1.PubKey"):
+		if len(decoded) != secp256k1.PubKeySize {
+			return nil, errors.New(fmt.Sprintf("invalid secp256k1 key length %d", len(decoded)))
+		}
+		return (&secp256k1.PubKey{Key: decoded}).Address(), nil
+	}
+	return nil, errors.New(fmt.Sprintf("unsupported consensus key type %s", keyType))
+}
+
+// accountValidator is a configured account that is a validator
+type accountValidator struct {
+	validator api.ValidatorInfo
+	// consAddress is the raw consensus address and consBech32 its bech32 encoding (e.g. cosmosvalcons1...)
+	consAddress []byte
+	consBech32  string
+}
+
+// accountValidators returns the accounts of the chain that are validators, with their consensus address
+func (c *Chain) accountValidators(client *http.Client) ([]accountValidator, error) {
+	var validators []accountValidator
+	for _, acct := range c.Accounts {
+		validator, err := c.ValidatorInfo(acct.Valoper, "", c.Querier)
+		if err != nil {
+			// accounts that are not validators have no signing info
+			if strings.Contains(err.Error(), "404") {
+				log.Debug().Msg(fmt.Sprintf("%s is not a validator on %s", acct.Name, c.Id))
+				continue
+			}
+			return validators, errors.New(fmt.Sprintf("query validator %s: %s", acct.Valoper, err))
+		}
+
+		address, err := ConsensusAddress(validator.ConsensusPubkey.Type, validator.ConsensusPubkey.Key)
+		if err != nil {
+			return validators, errors.New(fmt.Sprintf("consensus address of %s: %s", acct.Valoper, err))
+		}
+		encoded, err := bech32.ConvertAndEncode(c.Bech32Prefix+"valcons", address)
+		if err != nil {
+			return validators, err
+		}
+		validators = append(validators, accountValidator{validator: validator, consAddress: address, consBech32: encoded})
+	}
+	return validators, nil
+}
+
+// FetchValidatorUptime loads the liveness of every account of the chain that is a validator
+func (c *Chain) FetchValidatorUptime(client *http.Client) ([]*Uptime, error) {
+	var uptimes []*Uptime
+
+	params := api.SlashingParamsResponse{}
+	if err := c.Querier.SlashingParams(&params); err != nil {
+		return nil, errors.New(fmt.Sprintf("query slashing params: %s", err))
+	}
+
+	window, err := strconv.ParseInt(params.Params.SignedBlocksWindow, 10, 64)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("cannot parse signed blocks window %s", params.Params.SignedBlocksWindow))
+	}
+	minSigned, err := sdkmath.LegacyNewDecFromStr(params.Params.MinSignedPerWindow)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("cannot parse min signed per window %s", params.Params.MinSignedPerWindow))
+	}
+
+	blockInfo := api.BlockResponse{}
+	if err = c.Querier.LatestBlock(&blockInfo); err != nil {
+		return nil, errors.New(fmt.Sprintf("query latest block: %s", err))
+	}
+
+	validators, err := c.accountValidators(client)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range validators {
+		info := api.SigningInfoResponse{}
+		if err = c.Querier.SigningInfo(v.consBech32, &info); err != nil {
+			return uptimes, errors.New(fmt.Sprintf("query signing info of %s: %s", v.consBech32, err))
+		}
+
+		missed, err := strconv.ParseInt(info.ValSigningInfo.MissedBlocksCounter, 10, 64)
+		if err != nil {
+			return uptimes, errors.New(fmt.Sprintf("cannot parse missed blocks counter %s", info.ValSigningInfo.MissedBlocksCounter))
+		}
+
+		uptimes = append(uptimes, &Uptime{
+			Chain:              c,
+			Moniker:            v.validator.Description.Moniker,
+			ValoperAddress:     v.validator.OperatorAddress,
+			ConsAddress:        v.consBech32,
+			BlockTime:          blockInfo.Block.Header.Time,
+			BlockHeight:        blockInfo.Block.Header.Height,
+			MissedBlocks:       missed,
+			SignedBlocksWindow: window,
+			MinSignedPerWindow: minSigned,
+			Jailed:             v.validator.Jailed,
+			JailedUntil:        info.ValSigningInfo.JailedUntil,
+			Tombstoned:         info.ValSigningInfo.Tombstoned,
+		})
+	}
+	return uptimes, nil
+}
@@ -1,7 +1,17 @@
 package model
 
 import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/informalsystems/stakooler/client/cosmos/api"
+	"github.com/rs/zerolog/log"
 )
 
 type ValidatorList struct {
@@ -9,8 +19,10 @@ type ValidatorList struct {
 }
 
 type Validator struct {
+	Chain          *Chain
 	Moniker        string
 	ValoperAddress string
+	Denom          string
 	BlockTime      time.Time
 	BlockHeight    string
 	VotingPower    int64
@@ -22,94 +34,115 @@ type Validator struct {
 	Commission     float64
 }
 
-/*func LoadValidatorStats(validator *model.Validator, bar *progressbar.ProgressBar) error {
+// FetchValidatorStats loads the statistics for every account of the chain that is part of the active validator set
+func (c *Chain) FetchValidatorStats(client *http.Client) ([]*Validator, error) {
+	var stats []*Validator
+
+	// the validator set and per validator statistics are only queried over REST
+	if c.RestEndpoint == "" {
+		return nil, errors.New("no rest endpoint to query validators")
+	}
 
-	// Get chain validators
-	validators, err := api.GetChainValidators("standin")
+	validators, err := api.GetChainValidators(c.RestEndpoint, client)
 	if err != nil {
-		log.Error().Err(err).Msg("error getting validators")
-		return err
+		return nil, errors.New(fmt.Sprintf("query validators: %s", err))
+	}
+
+	blockInfo := api.BlockResponse{}
+	if err = c.Querier.LatestBlock(&blockInfo); err != nil {
+		return nil, errors.New(fmt.Sprintf("query latest block: %s", err))
 	}
-	bar.Add(1)
 
-	var totalVotingPower int64
+	symbol, exponent := GetDenomMetadata(c.BondDenom, c, client)
+	c.Exponent = exponent
+
+	totalVotingPower := new(big.Int)
+	votingPower := make(map[string]*big.Int, len(validators.ValidatorsResponse))
+	for _, val := range validators.ValidatorsResponse {
+		tokens, ok := new(big.Int).SetString(val.Tokens, 10)
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("cannot parse tokens for validator %s", val.OperatorAddress))
+		}
+		votingPower[val.OperatorAddress] = tokens
+		totalVotingPower.Add(totalVotingPower, tokens)
+	}
 
 	// Sort validators by voting power (descending)
-	sort.Slice(validators.ValidatorsResponse, func(i, j int) bool {
-		tI, _ := strconv.ParseInt(validators.ValidatorsResponse[i].Tokens[:len(validators.ValidatorsResponse[i].Tokens)-validator.Chain.Exponent], 10, 64)
-		tJ, _ := strconv.ParseInt(validators.ValidatorsResponse[j].Tokens[:len(validators.ValidatorsResponse[j].Tokens)-validator.Chain.Exponent], 10, 64)
-		return tI >= tJ
+	sort.SliceStable(validators.ValidatorsResponse, func(i, j int) bool {
+		return votingPower[validators.ValidatorsResponse[i].OperatorAddress].Cmp(votingPower[validators.ValidatorsResponse[j].OperatorAddress]) > 0
 	})
 
-	// Get total voting power
-	for i, val := range validators.ValidatorsResponse {
-		tokenConverted, err := strconv.ParseInt(val.Tokens[:len(val.Tokens)-validator.Chain.Exponent], 10, 64)
-		if err != nil {
-			log.Error().Err(err).Str("validator_addr", val.OperatorAddress).Msg("cannot convert tokens for voting power")
-			return err
+	for _, acct := range c.Accounts {
+		rank := -1
+		for i := range validators.ValidatorsResponse {
+			if strings.EqualFold(validators.ValidatorsResponse[i].OperatorAddress, acct.Valoper) {
+				rank = i
+				break
+			}
 		}
 
-		if strings.ToLower(val.OperatorAddress) == strings.ToLower(validator.ValoperAddress) {
-			validator.VotingPower = tokenConverted
-			validator.Ranking = i + 1
-			validator.Moniker = val.Description.Moniker
-			commission, err := strconv.ParseFloat(val.Commission.CommissionRates.Rate, 10)
-			if err != nil {
-				log.Error().Err(err).Str("validator_addr", val.OperatorAddress).Msg("cannot convert commission rate from string to float")
-			} else {
-				validator.Commission = commission * 100.0
-			}
+		// accounts that are not part of the active set are not validators we care about
+		if rank == -1 {
+			continue
 		}
 
-		totalVotingPower += tokenConverted
-	}
+		val := validators.ValidatorsResponse[rank]
+		tokens := votingPower[val.OperatorAddress]
+
+		validator := &Validator{
+			Chain:          c,
+			Moniker:        val.Description.Moniker,
+			ValoperAddress: val.OperatorAddress,
+			Denom:          symbol,
+			BlockTime:      blockInfo.Block.Header.Time,
+			BlockHeight:    blockInfo.Block.Header.Height,
+			VotingPower:    toDisplayUnits(tokens, exponent).Int64(),
+			Ranking:        rank + 1,
+			NumValidators:  strconv.Itoa(len(validators.ValidatorsResponse)),
+		}
 
-	// Find the voting power percent
-	votingPowerShare := float64(validator.VotingPower) / float64(totalVotingPower) * 100.0
-	validator.VotingPercent = votingPowerShare
+		if totalVotingPower.Sign() > 0 {
+			share, _ := new(big.Float).Quo(new(big.Float).SetInt(tokens), new(big.Float).SetInt(totalVotingPower)).Float64()
+			validator.VotingPercent = share * 100.0
+		}
 
-	// Get unbondings
-	unbondings, err := api.GetValidatorUnbondings(validator)
-	if err != nil {
-		return err
-	}
-	bar.Add(1)
-
-	var totalUnbondings int64
-
-	// Get total voting power
-	for _, unbonding := range unbondings.UnbondingResponses {
-
-		for _, entry := range unbonding.Entries {
-			if len(entry.BankBalance) > validator.Chain.Exponent {
-				unbonding := entry.BankBalance[:len(entry.BankBalance)-validator.Chain.Exponent]
-				if unbonding != "" {
-					unbondingConverted, err := strconv.ParseInt(unbonding, 10, 64)
-					if err != nil {
-						return errors.New(fmt.Sprintf("cannot convert unbondings: %s", err))
-					}
-					totalUnbondings += unbondingConverted
-				}
-			}
+		commission, err := strconv.ParseFloat(val.Commission.CommissionRates.Rate, 64)
+		if err != nil {
+			log.Error().Err(err).Str("validator_addr", val.OperatorAddress).Msg("cannot convert commission rate from string to float")
+		} else {
+			validator.Commission = commission * 100.0
 		}
-	}
-	validator.Unbondings = totalUnbondings
 
-	// Get block time
-	validator.BlockHeight = validators.BlockHeight
-	block, _ := api.GetBlock(validator.BlockHeight, validator.Chain.RestEndpoint)
-	bar.Add(1)
+		unbondings, err := api.GetValidatorUnbondings(c.RestEndpoint, val.OperatorAddress, client)
+		if err != nil {
+			return stats, errors.New(fmt.Sprintf("query validator unbondings: %s", err))
+		}
 
-	validator.BlockTime = block.Block.Header.Time
+		totalUnbondings := new(big.Int)
+		for _, unbonding := range unbondings.UnbondingResponses {
+			for _, entry := range unbonding.Entries {
+				balance, ok := new(big.Int).SetString(entry.Balance, 10)
+				if !ok {
+					return stats, errors.New(fmt.Sprintf("cannot convert unbonding balance: %s", entry.Balance))
+				}
+				totalUnbondings.Add(totalUnbondings, balance)
+			}
+		}
+		validator.Unbondings = toDisplayUnits(totalUnbondings, exponent).Int64()
 
-	// Get number of delegators
-	delegations, _ := api.GetValidatorDelegations(validator)
-	bar.Add(1)
-	validator.NumDelegators = delegations.Pagination.Total
+		delegators, err := api.GetValidatorDelegatorCount(c.RestEndpoint, val.OperatorAddress, client)
+		if err != nil {
+			return stats, errors.New(fmt.Sprintf("query validator delegations: %s", err))
+		}
+		validator.NumDelegators = strconv.Itoa(delegators)
 
-	// Get number of total validators
-	validator.NumValidators = validators.Pagination.Total
+		stats = append(stats, validator)
+	}
+	return stats, nil
+}
 
-	return nil
+// toDisplayUnits converts an amount of base units to whole display units, dropping the fractional part
+func toDisplayUnits(amount *big.Int, exponent int) *big.Int {
+	divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+	return new(big.Int).Quo(amount, divisor)
 }
-*/
@@ -0,0 +1,228 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	"github.com/informalsystems/stakooler/client/cosmos/api"
+)
+
+// vesting account types
+const (
+	ContinuousVesting = "continuous"
+	DelayedVesting    = "delayed"
+	PeriodicVesting   = "periodic"
+	PermanentLocked   = "permanent_locked"
+)
+
+// VestingSchedule describes how the original vesting of a vesting account unlocks, following the
+// rules of the cosmos-sdk vesting module
+type VestingSchedule struct {
+	Type            string
+	StartTime       time.Time
+	EndTime         time.Time
+	OriginalVesting map[string]sdkmath.Int
+	// Denoms describes the vesting denoms, to show amounts in display units
+	Denoms map[string]DenomInfo
+	// Periods of a periodic vesting account, with the time they end at
+	Periods []VestingPeriod
+}
+
+type VestingPeriod struct {
+	End    time.Time
+	Amount map[string]sdkmath.Int
+}
+
+// Unlock is an amount becoming vested at a point in time
+type Unlock struct {
+	Time   time.Time
+	Amount map[string]sdkmath.Int
+}
+
+// NewVestingSchedule returns the vesting schedule of the account, nil when it is not a vesting account
+func NewVestingSchedule(acct *api.AcctResponse) (*VestingSchedule, error) {
+	schedule := &VestingSchedule{Denoms: make(map[string]DenomInfo)}
+	accountType := acct.Account.Type[strings.LastIndex(acct.Account.Type, ".")+1:]
+	switch accountType {
+	case "ContinuousVestingAccount":
+		schedule.Type = ContinuousVesting
+	case "DelayedVestingAccount":
+		schedule.Type = DelayedVesting
+	case "PeriodicVestingAccount":
+		schedule.Type = PeriodicVesting
+	case "PermanentLockedAccount":
+		schedule.Type = PermanentLocked
+	default:
+		return nil, nil
+	}
+
+	var err error
+	base := acct.Account.BaseVestingAccount
+	if schedule.OriginalVesting, err = toCoins(base.OriginalVesting); err != nil {
+		return nil, err
+	}
+	if schedule.EndTime, err = parseUnixTime(base.EndTime); err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid vesting end time: %s", err))
+	}
+	if schedule.Type == ContinuousVesting || schedule.Type == PeriodicVesting {
+		if schedule.StartTime, err = parseUnixTime(acct.Account.StartTime); err != nil {
+			return nil, errors.New(fmt.Sprintf("invalid vesting start time: %s", err))
+		}
+	}
+
+	end := schedule.StartTime
+	for _, period := range acct.Account.VestingPeriods {
+		length, err := strconv.ParseInt(period.Length, 10, 64)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("invalid vesting period length %s: %s", period.Length, err))
+		}
+		amount, err := toCoins(period.Amount)
+		if err != nil {
+			return nil, err
+		}
+		end = end.Add(time.Duration(length) * time.Second)
+		schedule.Periods = append(schedule.Periods, VestingPeriod{End: end, Amount: amount})
+	}
+	return schedule, nil
+}
+
+// SortedDenoms returns the vesting denoms in alphabetical order
+func (s *VestingSchedule) SortedDenoms() []string {
+	denoms := make([]string, 0, len(s.OriginalVesting))
+	for denom := range s.OriginalVesting {
+		denoms = append(denoms, denom)
+	}
+	sort.Strings(denoms)
+	return denoms
+}
+
+// Vested returns the amount of denom vested at t
+func (s *VestingSchedule) Vested(denom string, t time.Time) sdkmath.Int {
+	original, ok := s.OriginalVesting[denom]
+	if !ok {
+		return sdkmath.ZeroInt()
+	}
+
+	switch s.Type {
+	case ContinuousVesting:
+		if !t.After(s.StartTime) {
+			return sdkmath.ZeroInt()
+		}
+		if !t.Before(s.EndTime) {
+			return original
+		}
+		elapsed := sdkmath.NewInt(t.Unix() - s.StartTime.Unix())
+		duration := sdkmath.NewInt(s.EndTime.Unix() - s.StartTime.Unix())
+		return original.Mul(elapsed).Quo(duration)
+	case DelayedVesting:
+		if !t.Before(s.EndTime) {
+			return original
+		}
+		return sdkmath.ZeroInt()
+	case PeriodicVesting:
+		if t.Before(s.StartTime) {
+			return sdkmath.ZeroInt()
+		}
+		if !t.Before(s.EndTime) {
+			return original
+		}
+		vested := sdkmath.ZeroInt()
+		for _, period := range s.Periods {
+			if t.Before(period.End) {
+				break
+			}
+			if amount, ok := period.Amount[denom]; ok {
+				vested = vested.Add(amount)
+			}
+		}
+		return vested
+	}
+
+	// permanently locked
+	return sdkmath.ZeroInt()
+}
+
+// Locked returns the amount of denom still vesting at t
+func (s *VestingSchedule) Locked(denom string, t time.Time) sdkmath.Int {
+	original, ok := s.OriginalVesting[denom]
+	if !ok {
+		return sdkmath.ZeroInt()
+	}
+	return original.Sub(s.Vested(denom, t))
+}
+
+// Calendar returns the unlocks of the schedule after from: every period of a periodic account, the end of a
+// delayed account and the amount vested by the end of every month for a continuous account
+func (s *VestingSchedule) Calendar(from time.Time) []Unlock {
+	var times []time.Time
+	switch s.Type {
+	case PeriodicVesting:
+		for _, period := range s.Periods {
+			times = append(times, period.End)
+		}
+	case DelayedVesting:
+		times = append(times, s.EndTime)
+	case ContinuousVesting:
+		start := s.StartTime.UTC()
+		month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+		for ; month.Before(s.EndTime); month = month.AddDate(0, 1, 0) {
+			times = append(times, month)
+		}
+		times = append(times, s.EndTime)
+	}
+
+	var unlocks []Unlock
+	previous := from
+	for _, t := range times {
+		if !t.After(from) {
+			continue
+		}
+		unlock := Unlock{Time: t, Amount: make(map[string]sdkmath.Int)}
+		for denom := range s.OriginalVesting {
+			amount := s.Vested(denom, t).Sub(s.Vested(denom, previous))
+			if amount.IsPositive() {
+				unlock.Amount[denom] = amount
+			}
+		}
+		if len(unlock.Amount) > 0 {
+			unlocks = append(unlocks, unlock)
+		}
+		previous = t
+	}
+	return unlocks
+}
+
+func toCoins(coins []struct {
+	Denom  string `json:"denom"`
+	Amount string `json:"amount"`
+}) (map[string]sdkmath.Int, error) {
+	amounts := make(map[string]sdkmath.Int)
+	for _, coin := range coins {
+		amount, ok := sdkmath.NewIntFromString(coin.Amount)
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("cannot parse amount %s of %s", coin.Amount, coin.Denom))
+		}
+		if existing, ok := amounts[coin.Denom]; ok {
+			amount = amount.Add(existing)
+		}
+		amounts[coin.Denom] = amount
+	}
+	return amounts, nil
+}
+
+// parseUnixTime parses the unix timestamps (in seconds) of vesting accounts
+func parseUnixTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
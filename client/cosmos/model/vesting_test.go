@@ -0,0 +1,198 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	"github.com/informalsystems/stakooler/client/cosmos/api"
+)
+
+var vestingStart = time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+func days(n int) time.Duration {
+	return time.Duration(n) * 24 * time.Hour
+}
+
+func coins(denom string, amount int64) map[string]sdkmath.Int {
+	return map[string]sdkmath.Int{denom: sdkmath.NewInt(amount)}
+}
+
+// testSchedules are a schedule of every vesting account type. The continuous schedule vests 100000 uatom a day
+// over 60 days, the periodic one 100, 200 and 300 uatom every 30 days
+func testSchedules() map[string]*VestingSchedule {
+	return map[string]*VestingSchedule{
+		ContinuousVesting: {
+			Type:            ContinuousVesting,
+			StartTime:       vestingStart,
+			EndTime:         vestingStart.Add(days(60)),
+			OriginalVesting: coins("uatom", 6000000),
+		},
+		DelayedVesting: {
+			Type:            DelayedVesting,
+			EndTime:         vestingStart.Add(days(60)),
+			OriginalVesting: coins("uatom", 500),
+		},
+		PeriodicVesting: {
+			Type:            PeriodicVesting,
+			StartTime:       vestingStart,
+			EndTime:         vestingStart.Add(days(90)),
+			OriginalVesting: coins("uatom", 600),
+			Periods: []VestingPeriod{
+				{End: vestingStart.Add(days(30)), Amount: coins("uatom", 100)},
+				{End: vestingStart.Add(days(60)), Amount: coins("uatom", 200)},
+				{End: vestingStart.Add(days(90)), Amount: coins("uatom", 300)},
+			},
+		},
+		PermanentLocked: {
+			Type:            PermanentLocked,
+			OriginalVesting: coins("uatom", 700),
+		},
+	}
+}
+
+func TestVestedAndLocked(t *testing.T) {
+	tests := []struct {
+		schedule string
+		at       time.Time
+		vested   int64
+	}{
+		{ContinuousVesting, vestingStart.Add(-time.Hour), 0},
+		{ContinuousVesting, vestingStart, 0},
+		{ContinuousVesting, vestingStart.Add(days(1)), 100000},
+		{ContinuousVesting, vestingStart.Add(days(30)), 3000000},
+		{ContinuousVesting, vestingStart.Add(days(60) - time.Second), 5999998},
+		{ContinuousVesting, vestingStart.Add(days(60)), 6000000},
+		{ContinuousVesting, vestingStart.Add(days(365)), 6000000},
+
+		{DelayedVesting, vestingStart.Add(-time.Hour), 0},
+		{DelayedVesting, vestingStart.Add(days(60) - time.Second), 0},
+		{DelayedVesting, vestingStart.Add(days(60)), 500},
+		{DelayedVesting, vestingStart.Add(days(365)), 500},
+
+		{PeriodicVesting, vestingStart.Add(-time.Hour), 0},
+		{PeriodicVesting, vestingStart, 0},
+		{PeriodicVesting, vestingStart.Add(days(30) - time.Second), 0},
+		{PeriodicVesting, vestingStart.Add(days(30)), 100},
+		{PeriodicVesting, vestingStart.Add(days(45)), 100},
+		{PeriodicVesting, vestingStart.Add(days(60)), 300},
+		{PeriodicVesting, vestingStart.Add(days(90)), 600},
+		{PeriodicVesting, vestingStart.Add(days(365)), 600},
+
+		{PermanentLocked, vestingStart.Add(-time.Hour), 0},
+		{PermanentLocked, vestingStart.Add(days(365 * 10)), 0},
+	}
+
+	schedules := testSchedules()
+	for _, test := range tests {
+		schedule := schedules[test.schedule]
+		original := schedule.OriginalVesting["uatom"]
+
+		if got := schedule.Vested("uatom", test.at); !got.Equal(sdkmath.NewInt(test.vested)) {
+			t.Errorf("%s: Vested(%s) = %s, want %d", test.schedule, test.at, got, test.vested)
+		}
+		if got := schedule.Locked("uatom", test.at); !got.Equal(original.SubRaw(test.vested)) {
+			t.Errorf("%s: Locked(%s) = %s, want %s", test.schedule, test.at, got, original.SubRaw(test.vested))
+		}
+		if got := schedule.Vested("uosmo", test.at); !got.IsZero() {
+			t.Errorf("%s: Vested of a denom that does not vest = %s, want 0", test.schedule, got)
+		}
+	}
+}
+
+func TestCalendar(t *testing.T) {
+	type unlock struct {
+		at     time.Time
+		amount int64
+	}
+	tests := []struct {
+		schedule string
+		from     time.Time
+		want     []unlock
+	}{
+		// the continuous schedule unlocks by the end of every month
+		{ContinuousVesting, vestingStart.Add(-days(10)), []unlock{
+			{time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), 1700000},
+			{time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), 2900000},
+			{vestingStart.Add(days(60)), 1400000},
+		}},
+		{ContinuousVesting, vestingStart.Add(days(30)), []unlock{
+			{time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), 1600000},
+			{vestingStart.Add(days(60)), 1400000},
+		}},
+		{ContinuousVesting, vestingStart.Add(days(60)), nil},
+
+		{DelayedVesting, vestingStart, []unlock{{vestingStart.Add(days(60)), 500}}},
+		{DelayedVesting, vestingStart.Add(days(60)), nil},
+
+		{PeriodicVesting, vestingStart.Add(-time.Hour), []unlock{
+			{vestingStart.Add(days(30)), 100},
+			{vestingStart.Add(days(60)), 200},
+			{vestingStart.Add(days(90)), 300},
+		}},
+		{PeriodicVesting, vestingStart.Add(days(45)), []unlock{
+			{vestingStart.Add(days(60)), 200},
+			{vestingStart.Add(days(90)), 300},
+		}},
+		{PeriodicVesting, vestingStart.Add(days(90)), nil},
+
+		{PermanentLocked, vestingStart, nil},
+	}
+
+	schedules := testSchedules()
+	for _, test := range tests {
+		got := schedules[test.schedule].Calendar(test.from)
+		if len(got) != len(test.want) {
+			t.Errorf("%s: Calendar(%s) returned %d unlocks, want %d", test.schedule, test.from, len(got), len(test.want))
+			continue
+		}
+		for i, want := range test.want {
+			if !got[i].Time.Equal(want.at) || !got[i].Amount["uatom"].Equal(sdkmath.NewInt(want.amount)) {
+				t.Errorf("%s: unlock %d from %s = %s %s, want %s %d", test.schedule, i, test.from, got[i].Time, got[i].Amount["uatom"], want.at, want.amount)
+			}
+		}
+	}
+}
+
+func TestNewVestingSchedule(t *testing.T) {
+	body := `{"account": {
+		"@type": "/cosmos.vesting.v1beta1.PeriodicVestingAccount",
+		"base_vesting_account": {
+			"original_vesting": [{"denom": "uatom", "amount": "600"}],
+			"end_time": "1713052800"
+		},
+		"start_time": "1705276800",
+		"vesting_periods": [
+			{"length": "2592000", "amount": [{"denom": "uatom", "amount": "100"}]},
+			{"length": "2592000", "amount": [{"denom": "uatom", "amount": "200"}]},
+			{"length": "2592000", "amount": [{"denom": "uatom", "amount": "300"}]}
+		]
+	}}`
+	var acct api.AcctResponse
+	if err := json.Unmarshal([]byte(body), &acct); err != nil {
+		t.Fatal(err)
+	}
+
+	schedule, err := NewVestingSchedule(&acct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := testSchedules()[PeriodicVesting]
+	if schedule.Type != want.Type || !schedule.StartTime.Equal(want.StartTime) || !schedule.EndTime.Equal(want.EndTime) {
+		t.Errorf("schedule = %s from %s to %s, want %s from %s to %s", schedule.Type, schedule.StartTime, schedule.EndTime, want.Type, want.StartTime, want.EndTime)
+	}
+	if len(schedule.Periods) != len(want.Periods) {
+		t.Fatalf("schedule has %d periods, want %d", len(schedule.Periods), len(want.Periods))
+	}
+	for i, period := range want.Periods {
+		if !schedule.Periods[i].End.Equal(period.End) || !schedule.Periods[i].Amount["uatom"].Equal(period.Amount["uatom"]) {
+			t.Errorf("period %d ends %s with %s, want %s with %s", i, schedule.Periods[i].End, schedule.Periods[i].Amount["uatom"], period.End, period.Amount["uatom"])
+		}
+	}
+
+	acct.Account.Type = "/cosmos.auth.v1beta1.BaseAccount"
+	if schedule, err = NewVestingSchedule(&acct); schedule != nil || err != nil {
+		t.Errorf("schedule of a base account = %v, %v, want nil", schedule, err)
+	}
+}
@@ -2,147 +2,517 @@ package display
 
 import (
 	"encoding/csv"
+	"errors"
 	"fmt"
-	"log"
-	"os"
+	"io"
 	"time"
 
 	"github.com/informalsystems/stakooler/client/cosmos/model"
 )
 
-func WriteDollarValueReport(chains []*model.Chain) {
-	file, err := os.Create("dollar_value_report.csv")
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	defer func(File *os.File) {
-		err = File.Close()
-		if err != nil {
-			return
-		}
-	}(file)
+func WriteDollarValueReport(out io.Writer, chains []*model.Chain, currencies []string) error {
+	w := csv.NewWriter(out)
 
-	w := csv.NewWriter(file)
-	defer w.Flush()
-
-	header := []string{"account_name", "token", "rewards", "commissions", "total USD value", "total CAD value"}
-	if err = w.Write(header); err != nil {
-		log.Fatalln("error writing record to file", err)
+	header := []string{"account_name", "token", "rewards", "commissions"}
+	for _, currency := range currencies {
+		header = append(header, fmt.Sprintf("total %s value", currency))
+	}
+	header = append(header, "balance")
+	for _, currency := range currencies {
+		header = append(header, fmt.Sprintf("balance %s value", currency))
+	}
+	if err := w.Write(header); err != nil {
+		return errors.New(fmt.Sprintf("error writing header: %s", err))
 	}
 
+	// names keeps the accounts in the order they first appear
+	var names []string
 	accounts := make(map[string][]*model.Token)
 	for _, chain := range chains {
 		for _, account := range chain.Accounts {
 			if _, ok := accounts[account.Name]; !ok {
 				accounts[account.Name] = make([]*model.Token, 0)
+				names = append(names, account.Name)
 			}
-			for _, token := range account.Tokens {
+			for _, token := range account.SortedTokens() {
 				accounts[account.Name] = append(accounts[account.Name], token)
 			}
 		}
 	}
 
-	for name, account := range accounts {
-		for _, token := range account {
+	for _, name := range names {
+		for _, token := range accounts[name] {
 			record := []string{
 				name,
 				token.DisplayName,
-				fmt.Sprintf("%f", token.Balances.Rewards),
-				fmt.Sprintf("%f", token.Balances.Commission),
-				fmt.Sprintf("%f", (token.Balances.Rewards+token.Balances.Commission)*token.PriceUSD),
-				fmt.Sprintf("%f", (token.Balances.Rewards+token.Balances.Commission)*token.PriceCAD),
+				FormatAmount(token.Balances.Rewards, token.Exponent),
+				FormatAmount(token.Balances.Commission, token.Exponent),
+			}
+			for _, currency := range currencies {
+				record = append(record, fmt.Sprintf("%f", token.Value(token.Balances.Rewards.Add(token.Balances.Commission), token.Price(currency))))
+			}
+			record = append(record, FormatAmount(token.Total(), token.Exponent))
+			for _, currency := range currencies {
+				record = append(record, fmt.Sprintf("%f", token.Value(token.Total(), token.Price(currency))))
 			}
-			if err = w.Write(record); err != nil {
-				log.Fatalln("error writing record", err)
+			if err := w.Write(record); err != nil {
+				return errors.New(fmt.Sprintf("error writing record: %s", err))
 			}
 		}
 	}
+
+	w.Flush()
+	return w.Error()
 }
 
-func WriteAccountsCSV(chains []*model.Chain) {
-	w := csv.NewWriter(os.Stdout)
-	defer w.Flush()
+func WriteAccountsCSV(out io.Writer, chains []*model.Chain) error {
+	w := csv.NewWriter(out)
 
-	header := []string{"account_name", "account_address", "chain_id", "block_height", "block_time", "token", "balance", "rewards", "staked", "unbonding", "commissions", "original_vesting", "delegated_vesting", "total"}
+	header := []string{"account_name", "account_address", "chain_id", "block_height", "block_time", "token", "balance", "rewards", "staked", "unbonding", "commissions", "original_vesting", "delegated_vesting", "total", "spendable", "locked", "delegated_free", "redelegating", "withdraw_address"}
 	if err := w.Write(header); err != nil {
-		log.Fatalln("error writing record to file", err)
+		return errors.New(fmt.Sprintf("error writing header: %s", err))
 	}
 
 	for _, chain := range chains {
 		for _, acct := range chain.Accounts {
-			entries := acct.Tokens
+			entries := acct.SortedTokens()
 
 			// In case there is no token information
 			if len(entries) == 0 {
 				record := []string{
 					acct.Name, acct.Address, "na", "na", "na", "na", "na", "na", "na", "na",
-					"na", "na", "na", "na",
+					"na", "na", "na", "na", "na", "na", "na", "na", "na",
 				}
 				if err := w.Write(record); err != nil {
-					log.Fatalln("error writing record", err)
+					return errors.New(fmt.Sprintf("error writing record: %s", err))
 				}
 			} else {
-				for i := range acct.Tokens {
-					total := entries[i].Balances.Bank +
-						entries[i].Balances.Rewards +
-						entries[i].Balances.Delegated +
-						entries[i].Balances.Unbonding +
-						entries[i].Balances.Commission
+				for i := range entries {
+					total := entries[i].Total()
+					exponent := entries[i].Exponent
 					record := []string{
 						acct.Name,
 						acct.Address,
 						chain.Id,
 						acct.BlockHeight,
 						acct.BlockTime.Format(time.DateTime),
-						acct.Tokens[i].DisplayName,
-						fmt.Sprintf("%f", acct.Tokens[i].Balances.Bank),
-						fmt.Sprintf("%f", acct.Tokens[i].Balances.Rewards),
-						fmt.Sprintf("%f", acct.Tokens[i].Balances.Delegated),
-						fmt.Sprintf("%f", acct.Tokens[i].Balances.Unbonding),
-						fmt.Sprintf("%f", acct.Tokens[i].Balances.Commission),
-						fmt.Sprintf("%f", acct.Tokens[i].Balances.OriginalVesting),
-						fmt.Sprintf("%f", acct.Tokens[i].Balances.DelegatedVesting),
-						fmt.Sprintf("%f", total),
+						entries[i].DisplayName,
+						FormatAmount(entries[i].Balances.Bank, exponent),
+						FormatAmount(entries[i].Balances.Rewards, exponent),
+						FormatAmount(entries[i].Balances.Delegated, exponent),
+						FormatAmount(entries[i].Balances.Unbonding, exponent),
+						FormatAmount(entries[i].Balances.Commission, exponent),
+						FormatAmount(entries[i].Balances.OriginalVesting, exponent),
+						FormatAmount(entries[i].Balances.DelegatedVesting, exponent),
+						FormatAmount(total, exponent),
+						FormatAmount(entries[i].Balances.Spendable, exponent),
+						FormatAmount(entries[i].Balances.Locked, exponent),
+						FormatAmount(entries[i].Balances.DelegatedFree, exponent),
+						FormatAmount(entries[i].Balances.Redelegating, exponent),
+						acct.WithdrawAddress,
 					}
 					if err := w.Write(record); err != nil {
-						log.Fatalln("error writing record", err)
+						return errors.New(fmt.Sprintf("error writing record: %s", err))
 					}
 				}
 			}
 		}
 	}
-}
 
-/*func WriteValidatorCSV(validators *model.ValidatorList) {
+	w.Flush()
+	return w.Error()
+}
 
-	// Outputs to Stdout
-	w := csv.NewWriter(os.Stdout)
-	defer w.Flush()
+func WriteValidatorCSV(out io.Writer, validators *model.ValidatorList) error {
+	w := csv.NewWriter(out)
 
-	header := []string{"moniker", "chain_id", "valoper_address", "block_time", "block_height", "voting_power_tokens", "voting_power_percent", "ranking", "commission", "delegators", "unbondings"}
+	header := []string{"moniker", "chain_id", "valoper_address", "block_time", "block_height", "voting_power_tokens", "voting_power_percent", "ranking", "commission", "validators", "delegators", "unbondings"}
 	if err := w.Write(header); err != nil {
-		log.Fatalln("error writing record to file", err)
+		return errors.New(fmt.Sprintf("error writing header: %s", err))
 	}
-	for idx := range validators.Entries {
-		validator := validators.Entries[idx]
 
+	for _, validator := range validators.Entries {
 		record := []string{
 			validator.Moniker,
 			validator.Chain.Id,
 			validator.ValoperAddress,
-			validator.BlockTime.Format(time.RFC822),
+			validator.BlockTime.Format(time.DateTime),
 			validator.BlockHeight,
 			fmt.Sprintf("%d", validator.VotingPower),
 			fmt.Sprintf("%.2f", validator.VotingPercent),
 			fmt.Sprintf("%d", validator.Ranking),
 			fmt.Sprintf("%.2f", validator.Commission),
+			validator.NumValidators,
 			validator.NumDelegators,
 			fmt.Sprintf("%d", validator.Unbondings),
 		}
 		if err := w.Write(record); err != nil {
-			log.Fatalln("error writing record", err)
+			return errors.New(fmt.Sprintf("error writing record: %s", err))
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func WriteUptimeCSV(out io.Writer, uptimes []*model.Uptime) error {
+	w := csv.NewWriter(out)
+
+	header := []string{"moniker", "chain_id", "valoper_address", "consensus_address", "block_time", "block_height", "missed_blocks", "max_missed_blocks", "signed_blocks_window", "min_signed_per_window", "uptime_percent", "status", "tombstoned", "jailed_until"}
+	if err := w.Write(header); err != nil {
+		return errors.New(fmt.Sprintf("error writing header: %s", err))
+	}
+
+	for _, u := range uptimes {
+		record := []string{
+			u.Moniker,
+			u.Chain.Id,
+			u.ValoperAddress,
+			u.ConsAddress,
+			u.BlockTime.Format(time.DateTime),
+			u.BlockHeight,
+			fmt.Sprintf("%d", u.MissedBlocks),
+			fmt.Sprintf("%d", u.MaxMissedBlocks()),
+			fmt.Sprintf("%d", u.SignedBlocksWindow),
+			u.MinSignedPerWindow.String(),
+			fmt.Sprintf("%.2f", u.Percent()),
+			uptimeStatus(u),
+			fmt.Sprintf("%t", u.Tombstoned),
+			formatJailedUntil(u, time.DateTime),
+		}
+		if err := w.Write(record); err != nil {
+			return errors.New(fmt.Sprintf("error writing record: %s", err))
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func WriteSigningCSV(out io.Writer, histories []*model.SigningHistory) error {
+	w := csv.NewWriter(out)
+
+	header := []string{"moniker", "chain_id", "valoper_address", "consensus_address", "from_height", "to_height", "signed", "missed", "proposed", "signed_percent", "miss_streaks", "longest_streak"}
+	if err := w.Write(header); err != nil {
+		return errors.New(fmt.Sprintf("error writing header: %s", err))
+	}
+
+	for _, h := range histories {
+		record := []string{
+			h.Moniker,
+			h.Chain.Id,
+			h.ValoperAddress,
+			h.ConsAddress,
+			fmt.Sprintf("%d", h.FromHeight),
+			fmt.Sprintf("%d", h.ToHeight),
+			fmt.Sprintf("%d", h.Signed),
+			fmt.Sprintf("%d", h.Missed),
+			fmt.Sprintf("%d", h.Proposed),
+			fmt.Sprintf("%.2f", h.SignedPercent()),
+			fmt.Sprintf("%d", len(h.Streaks)),
+			fmt.Sprintf("%d", h.LongestStreak().Length()),
+		}
+		if err := w.Write(record); err != nil {
+			return errors.New(fmt.Sprintf("error writing record: %s", err))
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// WriteMissStreaksCSV writes a row per run of consecutive missed blocks, e.g. to correlate them with node incidents
+func WriteMissStreaksCSV(out io.Writer, histories []*model.SigningHistory) error {
+	w := csv.NewWriter(out)
+
+	header := []string{"moniker", "chain_id", "valoper_address", "consensus_address", "start_height", "end_height", "blocks", "start_time", "end_time"}
+	if err := w.Write(header); err != nil {
+		return errors.New(fmt.Sprintf("error writing header: %s", err))
+	}
+
+	for _, h := range histories {
+		for _, streak := range h.Streaks {
+			record := []string{
+				h.Moniker,
+				h.Chain.Id,
+				h.ValoperAddress,
+				h.ConsAddress,
+				fmt.Sprintf("%d", streak.StartHeight),
+				fmt.Sprintf("%d", streak.EndHeight),
+				fmt.Sprintf("%d", streak.Length()),
+				streak.StartTime.Format(time.RFC3339),
+				streak.EndTime.Format(time.RFC3339),
+			}
+			if err := w.Write(record); err != nil {
+				return errors.New(fmt.Sprintf("error writing record: %s", err))
+			}
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func WriteDelegationsCSV(out io.Writer, chains []*model.Chain) error {
+	w := csv.NewWriter(out)
+
+	header := []string{"account_name", "account_address", "chain_id", "block_height", "validator", "validator_address", "status", "jailed", "commission_rate", "token", "shares", "delegated", "rewards", "unbonding", "redelegating", "redelegation_lock"}
+	if err := w.Write(header); err != nil {
+		return errors.New(fmt.Sprintf("error writing header: %s", err))
+	}
+
+	for _, chain := range chains {
+		for _, acct := range chain.Accounts {
+			for _, d := range acct.Delegations {
+				record := []string{
+					acct.Name,
+					acct.Address,
+					chain.Id,
+					acct.BlockHeight,
+					d.Moniker,
+					d.ValidatorAddress,
+					d.Status,
+					fmt.Sprintf("%t", d.Jailed),
+					fmt.Sprintf("%.2f", d.CommissionRate),
+					d.DisplayName,
+					FormatAmount(d.Shares.TruncateInt(), d.Exponent),
+					FormatAmount(d.Amount, d.Exponent),
+					FormatAmount(d.Rewards, d.Exponent),
+					FormatAmount(d.Unbonding, d.Exponent),
+					FormatAmount(d.Redelegating, d.Exponent),
+					formatRedelegationLock(d),
+				}
+				if err := w.Write(record); err != nil {
+					return errors.New(fmt.Sprintf("error writing record: %s", err))
+				}
+			}
 		}
 	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// formatRedelegationLock returns the end of the redelegation lock of the delegation, empty when it is not locked
+func formatRedelegationLock(d *model.Delegation) string {
+	if !d.RedelegationLocked(time.Now()) {
+		return ""
+	}
+	return d.RedelegationLock.UTC().Format(time.RFC3339)
+}
+
+func WriteRedelegationsCSV(out io.Writer, chains []*model.Chain) error {
+	w := csv.NewWriter(out)
+
+	header := []string{"account_name", "account_address", "chain_id", "src_validator", "src_validator_address", "dst_validator", "dst_validator_address", "creation_height", "completion_time", "token", "initial_balance", "amount"}
+	if err := w.Write(header); err != nil {
+		return errors.New(fmt.Sprintf("error writing header: %s", err))
+	}
+
+	for _, chain := range chains {
+		for _, acct := range chain.Accounts {
+			for _, entry := range acct.Redelegations {
+				record := []string{
+					acct.Name,
+					acct.Address,
+					chain.Id,
+					entry.SrcMoniker,
+					entry.SrcValidatorAddress,
+					entry.DstMoniker,
+					entry.DstValidatorAddress,
+					fmt.Sprintf("%d", entry.CreationHeight),
+					entry.CompletionTime.UTC().Format(time.RFC3339),
+					entry.DisplayName,
+					FormatAmount(entry.InitialBalance, entry.Exponent),
+					FormatAmount(entry.Amount, entry.Exponent),
+				}
+				if err := w.Write(record); err != nil {
+					return errors.New(fmt.Sprintf("error writing record: %s", err))
+				}
+			}
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func WriteUnbondingCSV(out io.Writer, chains []*model.Chain) error {
+	w := csv.NewWriter(out)
+
+	header := []string{"account_name", "account_address", "chain_id", "validator", "validator_address", "creation_height", "completion_time", "token", "initial_balance", "amount"}
+	if err := w.Write(header); err != nil {
+		return errors.New(fmt.Sprintf("error writing header: %s", err))
+	}
+
+	for _, chain := range chains {
+		for _, acct := range chain.Accounts {
+			for _, entry := range acct.Unbondings {
+				record := []string{
+					acct.Name,
+					acct.Address,
+					chain.Id,
+					entry.Moniker,
+					entry.ValidatorAddress,
+					fmt.Sprintf("%d", entry.CreationHeight),
+					entry.CompletionTime.UTC().Format(time.RFC3339),
+					entry.DisplayName,
+					FormatAmount(entry.InitialBalance, entry.Exponent),
+					FormatAmount(entry.Amount, entry.Exponent),
+				}
+				if err := w.Write(record); err != nil {
+					return errors.New(fmt.Sprintf("error writing record: %s", err))
+				}
+			}
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func WriteUnbondingScheduleCSV(out io.Writer, chains []*model.Chain, period string) error {
+	w := csv.NewWriter(out)
+
+	header := []string{"chain_id", "token", period, "entries", "amount"}
+	if err := w.Write(header); err != nil {
+		return errors.New(fmt.Sprintf("error writing header: %s", err))
+	}
+
+	for _, row := range UnbondingSchedule(chains, period) {
+		record := []string{
+			row.ChainId,
+			row.DisplayName,
+			row.Start.Format(time.DateOnly),
+			fmt.Sprintf("%d", row.Entries),
+			FormatAmount(row.Amount, row.Exponent),
+		}
+		if err := w.Write(record); err != nil {
+			return errors.New(fmt.Sprintf("error writing record: %s", err))
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// WriteVestingCSV writes the vested and locked amounts of vesting accounts at their block time and at every date
+func WriteVestingCSV(out io.Writer, chains []*model.Chain, dates []time.Time) error {
+	w := csv.NewWriter(out)
+
+	header := []string{"account_name", "account_address", "chain_id", "type", "start_time", "end_time", "token", "original_vesting", "time", "vested", "locked"}
+	if err := w.Write(header); err != nil {
+		return errors.New(fmt.Sprintf("error writing header: %s", err))
+	}
+
+	for _, chain := range chains {
+		for _, acct := range chain.Accounts {
+			schedule := acct.Vesting
+			if schedule == nil {
+				continue
+			}
+			for _, denom := range schedule.SortedDenoms() {
+				info := schedule.Denoms[denom]
+				for _, t := range append([]time.Time{acct.BlockTime}, dates...) {
+					record := []string{
+						acct.Name,
+						acct.Address,
+						chain.Id,
+						schedule.Type,
+						formatVestingTime(schedule.StartTime, time.RFC3339),
+						formatVestingTime(schedule.EndTime, time.RFC3339),
+						info.DisplayName,
+						FormatAmount(schedule.OriginalVesting[denom], info.Exponent),
+						t.UTC().Format(time.RFC3339),
+						FormatAmount(schedule.Vested(denom, t), info.Exponent),
+						FormatAmount(schedule.Locked(denom, t), info.Exponent),
+					}
+					if err := w.Write(record); err != nil {
+						return errors.New(fmt.Sprintf("error writing record: %s", err))
+					}
+				}
+			}
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// WriteVestingCalendarCSV writes the upcoming unlocks of vesting accounts
+func WriteVestingCalendarCSV(out io.Writer, chains []*model.Chain) error {
+	w := csv.NewWriter(out)
+
+	header := []string{"account_name", "account_address", "chain_id", "unlock_time", "token", "amount", "locked_after"}
+	if err := w.Write(header); err != nil {
+		return errors.New(fmt.Sprintf("error writing header: %s", err))
+	}
+
+	for _, chain := range chains {
+		for _, acct := range chain.Accounts {
+			if acct.Vesting == nil {
+				continue
+			}
+			for _, unlock := range acct.Vesting.Calendar(acct.BlockTime) {
+				for _, denom := range acct.Vesting.SortedDenoms() {
+					amount, ok := unlock.Amount[denom]
+					if !ok {
+						continue
+					}
+					info := acct.Vesting.Denoms[denom]
+					record := []string{
+						acct.Name,
+						acct.Address,
+						chain.Id,
+						unlock.Time.UTC().Format(time.RFC3339),
+						info.DisplayName,
+						FormatAmount(amount, info.Exponent),
+						FormatAmount(acct.Vesting.Locked(denom, unlock.Time), info.Exponent),
+					}
+					if err := w.Write(record); err != nil {
+						return errors.New(fmt.Sprintf("error writing record: %s", err))
+					}
+				}
+			}
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func WriteProposalsCSV(out io.Writer, proposals []*model.GovProposal, deadline time.Duration) error {
+	w := csv.NewWriter(out)
+
+	header := []string{"chain_id", "proposal_id", "title", "voting_end_time", "ends_soon", "yes_percent", "no_percent", "no_with_veto_percent", "abstain_percent", "account_name", "voter", "voted", "vote"}
+	if err := w.Write(header); err != nil {
+		return errors.New(fmt.Sprintf("error writing header: %s", err))
+	}
+
+	now := time.Now()
+	for _, proposal := range proposals {
+		for _, vote := range proposal.Votes {
+			record := []string{
+				proposal.Chain.Id,
+				proposal.Id,
+				proposal.Title,
+				proposal.VotingEndTime.Format(time.DateTime),
+				fmt.Sprintf("%t", proposal.EndsWithin(deadline, now)),
+				fmt.Sprintf("%.2f", proposal.Percent(proposal.Yes)),
+				fmt.Sprintf("%.2f", proposal.Percent(proposal.No)),
+				fmt.Sprintf("%.2f", proposal.Percent(proposal.NoWithVeto)),
+				fmt.Sprintf("%.2f", proposal.Percent(proposal.Abstain)),
+				vote.Name,
+				vote.Voter,
+				fmt.Sprintf("%t", vote.Voted),
+				vote.Option(),
+			}
+			if err := w.Write(record); err != nil {
+				return errors.New(fmt.Sprintf("error writing record: %s", err))
+			}
+		}
+	}
+
+	w.Flush()
+	return w.Error()
 }
-*/
@@ -0,0 +1,213 @@
+package display
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/informalsystems/stakooler/client/cosmos/model"
+
+	sdkmath "cosmossdk.io/math"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "stakooler"
+
+var (
+	accountLabels   = []string{"chain", "chain_id", "account", "address"}
+	validatorLabels = []string{"chain", "chain_id", "moniker", "valoper"}
+
+	balanceDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "account", "balance"),
+		"Token amount of an account in display units, by balance type",
+		append(accountLabels, "denom", "symbol", "type"), nil)
+	tokenValueDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "account", "token_value"),
+		"Value of the total amount of a token held by an account",
+		append(accountLabels, "denom", "symbol", "currency"), nil)
+	accountValueDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "account", "value"),
+		"Value of all the tokens held by an account",
+		append(accountLabels, "currency"), nil)
+	priceDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "token", "price"),
+		"Price of a token",
+		[]string{"chain", "chain_id", "denom", "symbol", "currency", "source"}, nil)
+	blockHeightDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "chain", "block_height"),
+		"Height of the block balances were last queried at",
+		[]string{"chain", "chain_id"}, nil)
+	blockAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "chain", "block_age_seconds"),
+		"Seconds elapsed since the time of the block balances were last queried at",
+		[]string{"chain", "chain_id"}, nil)
+	votingPowerDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "validator", "voting_power"),
+		"Voting power of a validator in display units",
+		validatorLabels, nil)
+	votingPercentDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "validator", "voting_power_percent"),
+		"Voting power of a validator as a percentage of the active set",
+		validatorLabels, nil)
+	rankingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "validator", "ranking"),
+		"Ranking of a validator by voting power",
+		validatorLabels, nil)
+	commissionDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "validator", "commission_percent"),
+		"Commission rate of a validator",
+		validatorLabels, nil)
+	delegatorsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "validator", "delegators"),
+		"Number of delegators of a validator",
+		validatorLabels, nil)
+	validatorUnbondingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "validator", "unbonding"),
+		"Tokens being unbonded from a validator in display units",
+		validatorLabels, nil)
+	lastUpdateDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "last_update_timestamp_seconds"),
+		"Time of the last completed update",
+		nil, nil)
+	updateDurationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "update_duration_seconds"),
+		"Duration of the last completed update",
+		nil, nil)
+)
+
+// MetricsCollector exposes the latest fetched accounts and validators as prometheus gauges. Metrics are
+// built from the last update when scraped so a scrape never waits for the chains
+type MetricsCollector struct {
+	mu         sync.RWMutex
+	chains     []*model.Chain
+	validators *model.ValidatorList
+	currencies []string
+	updated    time.Time
+	duration   time.Duration
+}
+
+func NewMetricsCollector() *MetricsCollector {
+	return &MetricsCollector{validators: &model.ValidatorList{}}
+}
+
+// Update replaces the metrics with the result of an update that took duration
+func (m *MetricsCollector) Update(chains []*model.Chain, validators *model.ValidatorList, currencies []string, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.chains = chains
+	m.validators = validators
+	m.currencies = currencies
+	m.updated = time.Now()
+	m.duration = duration
+}
+
+func (m *MetricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		balanceDesc, tokenValueDesc, accountValueDesc, priceDesc, blockHeightDesc, blockAgeDesc,
+		votingPowerDesc, votingPercentDesc, rankingDesc, commissionDesc, delegatorsDesc, validatorUnbondingDesc,
+		lastUpdateDesc, updateDurationDesc,
+	} {
+		ch <- desc
+	}
+}
+
+func (m *MetricsCollector) Collect(ch chan<- prometheus.Metric) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.updated.IsZero() {
+		return
+	}
+
+	gauge := func(desc *prometheus.Desc, value float64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, labels...)
+	}
+
+	for _, chain := range m.chains {
+		var block *model.Account
+		// prices are the same for every account, they are only exposed once per token
+		prices := make(map[string]bool)
+
+		for _, acct := range chain.Accounts {
+			if acct.BlockHeight == "" {
+				// the account could not be fetched
+				continue
+			}
+			block = acct
+
+			labels := []string{chain.Name, chain.Id, acct.Name, acct.Address}
+			for _, token := range acct.SortedTokens() {
+				balances := []struct {
+					kind   string
+					amount sdkmath.Int
+				}{
+					{"bank", token.Balances.Bank},
+					{"spendable", token.Balances.Spendable},
+					{"locked", token.Balances.Locked},
+					{"rewards", token.Balances.Rewards},
+					{"delegated", token.Balances.Delegated},
+					{"unbonding", token.Balances.Unbonding},
+					{"redelegating", token.Balances.Redelegating},
+					{"commission", token.Balances.Commission},
+					{"vesting", token.Balances.OriginalVesting},
+					{"delegated_free", token.Balances.DelegatedFree},
+					{"delegated_vesting", token.Balances.DelegatedVesting},
+				}
+				for _, b := range balances {
+					gauge(balanceDesc, amountToFloat(b.amount, token.Exponent), append(labels, token.Denom, token.DisplayName, b.kind)...)
+				}
+
+				total := token.Total()
+				for _, currency := range m.currencies {
+					price, ok := token.Prices[currency]
+					if !ok || price.Rate == 0 {
+						// unknown price
+						continue
+					}
+					gauge(tokenValueDesc, token.Value(total, price.Rate), append(labels, token.Denom, token.DisplayName, currency)...)
+
+					if key := token.Denom + "/" + currency; !prices[key] {
+						prices[key] = true
+						gauge(priceDesc, price.Rate, chain.Name, chain.Id, token.Denom, token.DisplayName, currency, price.Source)
+					}
+				}
+			}
+
+			for _, currency := range m.currencies {
+				gauge(accountValueDesc, acct.Totals[currency], append(labels, currency)...)
+			}
+		}
+
+		if block != nil {
+			if height, err := strconv.ParseFloat(block.BlockHeight, 64); err == nil {
+				gauge(blockHeightDesc, height, chain.Name, chain.Id)
+			}
+			gauge(blockAgeDesc, time.Since(block.BlockTime).Seconds(), chain.Name, chain.Id)
+		}
+	}
+
+	for _, validator := range m.validators.Entries {
+		labels := []string{validator.Chain.Name, validator.Chain.Id, validator.Moniker, validator.ValoperAddress}
+		gauge(votingPowerDesc, float64(validator.VotingPower), labels...)
+		gauge(votingPercentDesc, validator.VotingPercent, labels...)
+		gauge(rankingDesc, float64(validator.Ranking), labels...)
+		gauge(commissionDesc, validator.Commission, labels...)
+		if delegators, err := strconv.ParseFloat(validator.NumDelegators, 64); err == nil {
+			gauge(delegatorsDesc, delegators, labels...)
+		}
+		gauge(validatorUnbondingDesc, float64(validator.Unbondings), labels...)
+	}
+
+	gauge(lastUpdateDesc, float64(m.updated.Unix()))
+	gauge(updateDurationDesc, m.duration.Seconds())
+}
+
+// amountToFloat converts an amount in base units to display units
+func amountToFloat(amount sdkmath.Int, exponent int) float64 {
+	value, err := strconv.ParseFloat(FormatAmount(amount, exponent), 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
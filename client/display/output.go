@@ -0,0 +1,100 @@
+package display
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultFileTemplate names report files after the report, e.g. dollar_value_report.csv
+const DefaultFileTemplate = "{report}.{ext}"
+
+// ReportFiles creates report files in Dir named after Template. The template supports the
+// {report}, {ext}, {date} (2006-01-02) and {timestamp} (20060102T150405Z, UTC) placeholders
+type ReportFiles struct {
+	Dir      string
+	Template string
+	// Force allows overwriting existing files
+	Force bool
+}
+
+// FileName returns the path of the report file for the given report name and extension
+func (r ReportFiles) FileName(report string, ext string, now time.Time) string {
+	template := r.Template
+	if template == "" {
+		template = DefaultFileTemplate
+	}
+
+	now = now.UTC()
+	name := strings.NewReplacer(
+		"{report}", report,
+		"{ext}", ext,
+		"{date}", now.Format(time.DateOnly),
+		"{timestamp}", now.Format("20060102T150405Z"),
+	).Replace(template)
+	return filepath.Join(r.Dir, name)
+}
+
+// Report is the name and the extension of a report written to a file
+type Report struct {
+	Name string
+	Ext  string
+}
+
+// Create creates the report file, refusing to overwrite an existing file unless Force is set
+func (r ReportFiles) Create(report string, ext string) (*os.File, error) {
+	files, err := r.CreateAll([]Report{{Name: report, Ext: ext}})
+	if err != nil {
+		return nil, err
+	}
+	return files[0], nil
+}
+
+// CreateAll creates the files of several reports before any of them is written, so a file that already exists
+// does not leave the other reports half written. The files created are removed when one of them cannot be created.
+// The template must contain {report} when there are several reports, they would all be written to one file otherwise
+func (r ReportFiles) CreateAll(reports []Report) ([]*os.File, error) {
+	if len(reports) > 1 && r.Template != "" && !strings.Contains(r.Template, "{report}") {
+		return nil, errors.New(fmt.Sprintf("file template %s has no {report} placeholder, the %d reports would be written to the same file", r.Template, len(reports)))
+	}
+
+	if r.Dir != "" && len(reports) > 0 {
+		if err := os.MkdirAll(r.Dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if r.Force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+
+	now := time.Now()
+	files := make([]*os.File, 0, len(reports))
+	for _, report := range reports {
+		path := r.FileName(report.Name, report.Ext, now)
+		file, err := os.OpenFile(path, flags, 0o644)
+		if err != nil {
+			if !r.Force {
+				RemoveFiles(files)
+			}
+			if errors.Is(err, os.ErrExist) {
+				return nil, errors.New(fmt.Sprintf("%s already exists, use --force to overwrite it", path))
+			}
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// RemoveFiles closes and removes report files, e.g. when their reports cannot be written
+func RemoveFiles(files []*os.File) {
+	for _, file := range files {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}
+}
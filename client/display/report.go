@@ -0,0 +1,180 @@
+package display
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/informalsystems/stakooler/client/cosmos/model"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AccountsReportVersion is the version of the json and yaml accounts report schema. It is increased
+// whenever a field is removed, renamed or changes meaning, adding fields does not change it
+const AccountsReportVersion = "1"
+
+type AccountsReport struct {
+	Version     string    `json:"version" yaml:"version"`
+	GeneratedAt time.Time `json:"generated_at" yaml:"generated_at"`
+	// Snapshot is omitted when the balances are the latest ones
+	Snapshot   *SnapshotReport `json:"snapshot,omitempty" yaml:"snapshot,omitempty"`
+	Currencies []string        `json:"currencies" yaml:"currencies"`
+	Chains     []ChainReport   `json:"chains" yaml:"chains"`
+}
+
+// SnapshotReport is the block height or time the balances were requested at. Prices are not historical, so the
+// values of a snapshot are its balances at the prices of the time the report was generated
+type SnapshotReport struct {
+	Height int64      `json:"height,omitempty" yaml:"height,omitempty"`
+	Time   *time.Time `json:"time,omitempty" yaml:"time,omitempty"`
+}
+
+type ChainReport struct {
+	Name      string          `json:"name" yaml:"name"`
+	Id        string          `json:"id" yaml:"id"`
+	BondDenom string          `json:"bond_denom" yaml:"bond_denom"`
+	Accounts  []AccountReport `json:"accounts" yaml:"accounts"`
+}
+
+type AccountReport struct {
+	Name    string `json:"name" yaml:"name"`
+	Address string `json:"address" yaml:"address"`
+	Valoper string `json:"valoper" yaml:"valoper"`
+	// WithdrawAddress receives the rewards and commissions of the account
+	WithdrawAddress string `json:"withdraw_address" yaml:"withdraw_address"`
+	BlockHeight     string `json:"block_height" yaml:"block_height"`
+	// BlockTime is omitted when the block could not be fetched
+	BlockTime *time.Time `json:"block_time,omitempty" yaml:"block_time,omitempty"`
+	// Values holds the value of all the account tokens per currency
+	Values map[string]float64 `json:"values" yaml:"values"`
+	Tokens []TokenReport      `json:"tokens" yaml:"tokens"`
+}
+
+// TokenReport holds the balances of a token in display units, as decimal strings so no precision is lost
+type TokenReport struct {
+	Symbol   string `json:"symbol" yaml:"symbol"`
+	Denom    string `json:"denom" yaml:"denom"`
+	Exponent int    `json:"exponent" yaml:"exponent"`
+	// BaseDenom, SourceChain and IbcPath are only set for IBC tokens
+	BaseDenom   string                 `json:"base_denom,omitempty" yaml:"base_denom,omitempty"`
+	SourceChain string                 `json:"source_chain,omitempty" yaml:"source_chain,omitempty"`
+	IbcPath     string                 `json:"ibc_path,omitempty" yaml:"ibc_path,omitempty"`
+	Prices      map[string]PriceReport `json:"prices" yaml:"prices"`
+	Balances    BalancesReport         `json:"balances" yaml:"balances"`
+	Total       string                 `json:"total" yaml:"total"`
+	// Values holds the value of the token total per currency
+	Values map[string]float64 `json:"values" yaml:"values"`
+}
+
+type PriceReport struct {
+	Rate   float64 `json:"rate" yaml:"rate"`
+	Source string  `json:"source" yaml:"source"`
+}
+
+type BalancesReport struct {
+	Bank             string `json:"bank" yaml:"bank"`
+	Spendable        string `json:"spendable" yaml:"spendable"`
+	Locked           string `json:"locked" yaml:"locked"`
+	Rewards          string `json:"rewards" yaml:"rewards"`
+	Staked           string `json:"staked" yaml:"staked"`
+	Unbonding        string `json:"unbonding" yaml:"unbonding"`
+	Redelegating     string `json:"redelegating" yaml:"redelegating"`
+	Commission       string `json:"commission" yaml:"commission"`
+	OriginalVesting  string `json:"original_vesting" yaml:"original_vesting"`
+	DelegatedFree    string `json:"delegated_free" yaml:"delegated_free"`
+	DelegatedVesting string `json:"delegated_vesting" yaml:"delegated_vesting"`
+}
+
+// NewAccountsReport builds the machine readable chain -> account -> token tree, snapshot is nil for the latest
+// balances
+func NewAccountsReport(chains []*model.Chain, currencies []string, snapshot *SnapshotReport) AccountsReport {
+	report := AccountsReport{
+		Version:     AccountsReportVersion,
+		GeneratedAt: time.Now().UTC(),
+		Snapshot:    snapshot,
+		Currencies:  currencies,
+		Chains:      make([]ChainReport, 0, len(chains)),
+	}
+
+	for _, chain := range chains {
+		chainReport := ChainReport{
+			Name:      chain.Name,
+			Id:        chain.Id,
+			BondDenom: chain.BondDenom,
+			Accounts:  make([]AccountReport, 0, len(chain.Accounts)),
+		}
+
+		for _, acct := range chain.Accounts {
+			acctReport := AccountReport{
+				Name:            acct.Name,
+				Address:         acct.Address,
+				Valoper:         acct.Valoper,
+				WithdrawAddress: acct.WithdrawAddress,
+				BlockHeight:     acct.BlockHeight,
+				Values:          make(map[string]float64),
+				Tokens:          make([]TokenReport, 0, len(acct.Tokens)),
+			}
+			if !acct.BlockTime.IsZero() {
+				blockTime := acct.BlockTime
+				acctReport.BlockTime = &blockTime
+			}
+
+			for _, token := range acct.SortedTokens() {
+				total := token.Total()
+
+				tokenReport := TokenReport{
+					Symbol:      token.DisplayName,
+					Denom:       token.Denom,
+					Exponent:    token.Exponent,
+					BaseDenom:   token.BaseDenom,
+					SourceChain: token.SourceChain,
+					IbcPath:     token.IbcPath,
+					Prices:      make(map[string]PriceReport),
+					Balances: BalancesReport{
+						Bank:             FormatAmount(token.Balances.Bank, token.Exponent),
+						Spendable:        FormatAmount(token.Balances.Spendable, token.Exponent),
+						Locked:           FormatAmount(token.Balances.Locked, token.Exponent),
+						Rewards:          FormatAmount(token.Balances.Rewards, token.Exponent),
+						Staked:           FormatAmount(token.Balances.Delegated, token.Exponent),
+						Unbonding:        FormatAmount(token.Balances.Unbonding, token.Exponent),
+						Redelegating:     FormatAmount(token.Balances.Redelegating, token.Exponent),
+						Commission:       FormatAmount(token.Balances.Commission, token.Exponent),
+						OriginalVesting:  FormatAmount(token.Balances.OriginalVesting, token.Exponent),
+						DelegatedFree:    FormatAmount(token.Balances.DelegatedFree, token.Exponent),
+						DelegatedVesting: FormatAmount(token.Balances.DelegatedVesting, token.Exponent),
+					},
+					Total:  FormatAmount(total, token.Exponent),
+					Values: make(map[string]float64),
+				}
+
+				for _, currency := range currencies {
+					price := token.Prices[currency]
+					tokenReport.Prices[currency] = PriceReport{Rate: price.Rate, Source: price.Source}
+					value := token.Value(total, price.Rate)
+					tokenReport.Values[currency] = value
+					acctReport.Values[currency] += value
+				}
+				acctReport.Tokens = append(acctReport.Tokens, tokenReport)
+			}
+			chainReport.Accounts = append(chainReport.Accounts, acctReport)
+		}
+		report.Chains = append(report.Chains, chainReport)
+	}
+	return report
+}
+
+func WriteAccountsJSON(out io.Writer, chains []*model.Chain, currencies []string, snapshot *SnapshotReport) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(NewAccountsReport(chains, currencies, snapshot))
+}
+
+func WriteAccountsYAML(out io.Writer, chains []*model.Chain, currencies []string, snapshot *SnapshotReport) error {
+	encoder := yaml.NewEncoder(out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(NewAccountsReport(chains, currencies, snapshot)); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
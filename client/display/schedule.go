@@ -0,0 +1,86 @@
+package display
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/informalsystems/stakooler/client/cosmos/model"
+
+	sdkmath "cosmossdk.io/math"
+)
+
+// schedule periods
+const (
+	PeriodDay  = "day"
+	PeriodWeek = "week"
+)
+
+// ScheduleRow is the amount of a token becoming liquid on a chain during a period
+type ScheduleRow struct {
+	ChainId     string
+	DisplayName string
+	Exponent    int
+	// Start of the period, in UTC
+	Start   time.Time
+	Amount  sdkmath.Int
+	Entries int
+}
+
+// PeriodStart returns the start of the day or ISO week (starting on monday) t belongs to, in UTC
+func PeriodStart(t time.Time, period string) time.Time {
+	day := time.Date(t.UTC().Year(), t.UTC().Month(), t.UTC().Day(), 0, 0, 0, 0, time.UTC)
+	if period != PeriodWeek {
+		return day
+	}
+	// time.Sunday is 0
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// PeriodLabel formats the start of a period, weeks also show their ISO week number
+func PeriodLabel(start time.Time, period string) string {
+	if period != PeriodWeek {
+		return start.Format(time.DateOnly)
+	}
+	_, week := start.ISOWeek()
+	return fmt.Sprintf("%s (W%02d)", start.Format(time.DateOnly), week)
+}
+
+// UnbondingSchedule sums the unbonding entries of all accounts per chain, token and period
+func UnbondingSchedule(chains []*model.Chain, period string) []*ScheduleRow {
+	var rows []*ScheduleRow
+	for _, chain := range chains {
+		byKey := make(map[string]*ScheduleRow)
+		var chainRows []*ScheduleRow
+		for _, acct := range chain.Accounts {
+			for _, entry := range acct.Unbondings {
+				start := PeriodStart(entry.CompletionTime, period)
+				key := entry.DisplayName + "/" + start.String()
+				row, ok := byKey[key]
+				if !ok {
+					row = &ScheduleRow{
+						ChainId:     chain.Id,
+						DisplayName: entry.DisplayName,
+						Exponent:    entry.Exponent,
+						Start:       start,
+						Amount:      sdkmath.ZeroInt(),
+					}
+					byKey[key] = row
+					chainRows = append(chainRows, row)
+				}
+				row.Amount = row.Amount.Add(entry.Amount)
+				row.Entries++
+			}
+		}
+
+		sort.SliceStable(chainRows, func(i, j int) bool {
+			if !chainRows[i].Start.Equal(chainRows[j].Start) {
+				return chainRows[i].Start.Before(chainRows[j].Start)
+			}
+			return chainRows[i].DisplayName < chainRows[j].DisplayName
+		})
+		rows = append(rows, chainRows...)
+	}
+	return rows
+}
@@ -4,59 +4,82 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/informalsystems/stakooler/client/cosmos/model"
 
+	sdkmath "cosmossdk.io/math"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jedib0t/go-pretty/v6/text"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
 )
 
-func PrintAccountDetailsTable(chains []*model.Chain) {
+func PrintAccountDetailsTable(chains []*model.Chain, currencies []string) {
 	for _, chain := range chains {
 		t := table.NewWriter()
 		t.SetOutputMirror(os.Stdout)
 		t.SetTitle(strings.ToUpper(fmt.Sprintf("%d accounts for %s", len(chain.Accounts), chain.Name)))
-		t.AppendHeader(table.Row{"Name", "Account", "Token", "Balance", "Rewards", "Staked", "Unbonding", "Commissions", "Original Vesting", "Delegated Vesting", "Total", "Total USD", "Total CAD"})
 
+		header := table.Row{"Name", "Account", "Withdraw Address", "Token", "Balance", "Spendable", "Locked", "Rewards", "Staked", "Redelegating", "Unbonding", "Commissions", "Delegated Vesting", "Total"}
+		for _, currency := range currencies {
+			header = append(header, "Total "+currency)
+		}
+		t.AppendHeader(header)
+
+		external := 0
 		for _, account := range chain.Accounts {
-			for _, e := range account.Tokens {
-				total := e.Balances.OriginalVesting -
-					e.Balances.DelegatedVesting +
-					e.Balances.Bank +
-					e.Balances.Rewards +
-					e.Balances.Delegated +
-					e.Balances.Unbonding +
-					e.Balances.Commission
-				t.AppendRow([]interface{}{
+			withdraw := ""
+			if account.WithdrawAddress != account.Address {
+				withdraw = account.WithdrawAddress
+			}
+			if withdraw != "" && !chain.HasAccount(withdraw) {
+				external++
+				withdraw = text.FgYellow.Sprint(withdraw)
+			}
+			for _, e := range account.SortedTokens() {
+				total := e.Total()
+				row := table.Row{
 					account.Name,
 					account.Address,
+					withdraw,
 					e.DisplayName,
-					FilterZeroValue(e.Balances.Bank),
-					FilterZeroValue(e.Balances.Rewards),
-					FilterZeroValue(e.Balances.Delegated),
-					FilterZeroValue(e.Balances.Unbonding),
-					FilterZeroValue(e.Balances.Commission),
-					FilterZeroValue(e.Balances.OriginalVesting),
-					FilterZeroValue(e.Balances.DelegatedVesting),
-					FilterZeroValue(total),
-					FilterZeroValue(total * e.PriceUSD),
-					FilterZeroValue(total * e.PriceCAD),
-				})
+					FilterZeroAmount(e.Balances.Bank, e.Exponent),
+					FilterZeroAmount(e.Balances.Spendable, e.Exponent),
+					FilterZeroAmount(e.Balances.Locked, e.Exponent),
+					FilterZeroAmount(e.Balances.Rewards, e.Exponent),
+					FilterZeroAmount(e.Balances.Delegated, e.Exponent),
+					FilterZeroAmount(e.Balances.Redelegating, e.Exponent),
+					FilterZeroAmount(e.Balances.Unbonding, e.Exponent),
+					FilterZeroAmount(e.Balances.Commission, e.Exponent),
+					FilterZeroAmount(e.Balances.DelegatedVesting, e.Exponent),
+					FilterZeroAmount(total, e.Exponent),
+				}
+				for _, currency := range currencies {
+					row = append(row, FilterZeroValue(e.Value(total, e.Price(currency))))
+				}
+				t.AppendRow(row)
 
 			}
 			t.AppendSeparator()
 		}
+		if external > 0 {
+			t.SetCaption(fmt.Sprintf("%d accounts withdraw their rewards to an address that is not a configured account", external))
+		}
 
 		t.SetColumnConfigs([]table.ColumnConfig{
 			{Name: "Name", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
 			{Name: "Account", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+			{Name: "Withdraw Address", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
 			{Name: "Token", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
-			{Name: "BankBalance", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+			{Name: "Balance", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+			{Name: "Spendable", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+			{Name: "Locked", Align: text.AlignRight, AlignHeader: text.AlignCenter},
 			{Name: "Rewards", Align: text.AlignRight, AlignHeader: text.AlignCenter},
 			{Name: "Staked", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+			{Name: "Redelegating", Align: text.AlignRight, AlignHeader: text.AlignCenter},
 			{Name: "Unbonding", Align: text.AlignRight, AlignHeader: text.AlignCenter},
 			{Name: "Commissions", Align: text.AlignRight, AlignHeader: text.AlignCenter},
-			{Name: "Original Vesting", Align: text.AlignRight, AlignHeader: text.AlignCenter},
 			{Name: "Delegated Vesting", Align: text.AlignRight, AlignHeader: text.AlignCenter},
 			{Name: "Total", Align: text.AlignRight, AlignHeader: text.AlignCenter},
 		})
@@ -65,30 +88,28 @@ func PrintAccountDetailsTable(chains []*model.Chain) {
 	return
 }
 
-/*func PrintValidatorStasTable(validators *model.ValidatorList) {
+func PrintValidatorStatsTable(validators *model.ValidatorList) {
 	t := table.NewWriter()
 	t.SetOutputMirror(os.Stdout)
 	t.SetTitle(strings.ToUpper("Validator - Statistics"))
 	t.SetCaption(fmt.Sprintf("Retrieved information for %d validators", len(validators.Entries)))
 	t.AppendHeader(table.Row{"Moniker", "Chain", "Validator Address", "Block Time", "Block Height", "Voting Power (VP)", "VP (%)", "Ranking", "Commission", "# Validators", "Delegators", "Unbondings"})
 
-	for idx := range validators.Entries {
-		validator := validators.Entries[idx]
-		p := message.NewPrinter(language.English)
-
+	p := message.NewPrinter(language.English)
+	for _, validator := range validators.Entries {
 		t.AppendRow([]interface{}{
 			validator.Moniker,
 			validator.Chain.Id,
 			validator.ValoperAddress,
 			validator.BlockTime.Format(time.RFC822),
 			validator.BlockHeight,
-			p.Sprintf("%d (%s)", validator.VotingPower, validator.Chain.Denom),
+			p.Sprintf("%d (%s)", validator.VotingPower, validator.Denom),
 			p.Sprintf("%.2f", validator.VotingPercent),
 			p.Sprintf("%d", validator.Ranking),
 			p.Sprintf("%.2f", validator.Commission),
 			validator.NumValidators,
 			validator.NumDelegators,
-			p.Sprintf("%d (%s)", validator.Unbondings, validator.Chain.Denom),
+			p.Sprintf("%d (%s)", validator.Unbondings, validator.Denom),
 		})
 		t.AppendSeparator()
 	}
@@ -110,7 +131,434 @@ func PrintAccountDetailsTable(chains []*model.Chain) {
 	t.Render()
 	return
 }
-*/
+
+func PrintUptimeTable(uptimes []*model.Uptime) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetTitle(strings.ToUpper("Validator - Uptime"))
+	t.AppendHeader(table.Row{"Moniker", "Chain", "Validator Address", "Consensus Address", "Block Height", "Missed Blocks", "Max Missed", "Window", "Uptime (%)", "Status", "Jailed Until"})
+
+	atRisk := 0
+	p := message.NewPrinter(language.English)
+	for _, u := range uptimes {
+		status := uptimeStatus(u)
+		missed := p.Sprintf("%d", u.MissedBlocks)
+		if u.AtRisk() {
+			atRisk++
+			status = text.Colors{text.FgRed, text.Bold}.Sprint(strings.ToUpper(status))
+			missed = text.Colors{text.FgRed, text.Bold}.Sprint(missed)
+		}
+		t.AppendRow([]interface{}{
+			u.Moniker,
+			u.Chain.Id,
+			u.ValoperAddress,
+			u.ConsAddress,
+			u.BlockHeight,
+			missed,
+			p.Sprintf("%d", u.MaxMissedBlocks()),
+			p.Sprintf("%d", u.SignedBlocksWindow),
+			p.Sprintf("%.2f", u.Percent()),
+			status,
+			formatJailedUntil(u, time.RFC822),
+		})
+		t.AppendSeparator()
+	}
+	t.SetCaption(fmt.Sprintf("Retrieved information for %d validators, %d jailed, tombstoned or past half of their missed blocks", len(uptimes), atRisk))
+
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Name: "Moniker", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+		{Name: "Chain", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+		{Name: "Validator Address", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+		{Name: "Consensus Address", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+		{Name: "Block Height", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+		{Name: "Missed Blocks", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+		{Name: "Max Missed", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+		{Name: "Window", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+		{Name: "Uptime (%)", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+		{Name: "Status", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+		{Name: "Jailed Until", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+	})
+	t.Render()
+}
+
+func PrintSigningTable(histories []*model.SigningHistory) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetTitle(strings.ToUpper("Validator - Signing History"))
+	t.SetCaption(fmt.Sprintf("Scanned the commit signatures of %d validators", len(histories)))
+	t.AppendHeader(table.Row{"Moniker", "Chain", "Validator Address", "Heights", "Signed", "Missed", "Proposed", "Signed (%)", "Longest Streak"})
+
+	p := message.NewPrinter(language.English)
+	streaks := 0
+	for _, h := range histories {
+		missed := p.Sprintf("%d", h.Missed)
+		if h.Missed > 0 {
+			missed = text.Colors{text.FgRed, text.Bold}.Sprint(missed)
+		}
+		t.AppendRow([]interface{}{
+			h.Moniker,
+			h.Chain.Id,
+			h.ValoperAddress,
+			fmt.Sprintf("%d-%d", h.FromHeight, h.ToHeight),
+			p.Sprintf("%d", h.Signed),
+			missed,
+			p.Sprintf("%d", h.Proposed),
+			p.Sprintf("%.2f", h.SignedPercent()),
+			p.Sprintf("%d", h.LongestStreak().Length()),
+		})
+		t.AppendSeparator()
+		streaks += len(h.Streaks)
+	}
+
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Name: "Moniker", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+		{Name: "Chain", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+		{Name: "Validator Address", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+		{Name: "Heights", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+		{Name: "Signed", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+		{Name: "Missed", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+		{Name: "Proposed", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+		{Name: "Signed (%)", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+		{Name: "Longest Streak", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+	})
+	t.Render()
+
+	if streaks == 0 {
+		return
+	}
+
+	s := table.NewWriter()
+	s.SetOutputMirror(os.Stdout)
+	s.SetTitle(strings.ToUpper("Miss Streaks"))
+	s.AppendHeader(table.Row{"Moniker", "Chain", "From Height", "To Height", "Blocks", "From", "To"})
+	for _, h := range histories {
+		for _, streak := range h.Streaks {
+			s.AppendRow(table.Row{
+				h.Moniker,
+				h.Chain.Id,
+				streak.StartHeight,
+				streak.EndHeight,
+				streak.Length(),
+				streak.StartTime.Format(time.DateTime),
+				streak.EndTime.Format(time.DateTime),
+			})
+		}
+		s.AppendSeparator()
+	}
+	s.SetColumnConfigs([]table.ColumnConfig{
+		{Name: "Moniker", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+		{Name: "Chain", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+		{Name: "From Height", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+		{Name: "To Height", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+		{Name: "Blocks", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+		{Name: "From", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+		{Name: "To", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+	})
+	s.Render()
+}
+
+// uptimeStatus returns tombstoned, jailed or active
+func uptimeStatus(u *model.Uptime) string {
+	switch {
+	case u.Tombstoned:
+		return "tombstoned"
+	case u.Jailed:
+		return "jailed"
+	}
+	return "active"
+}
+
+// formatJailedUntil formats the end of the last jail period, empty when the validator was never jailed
+func formatJailedUntil(u *model.Uptime, layout string) string {
+	if !u.WasJailed() {
+		return ""
+	}
+	return u.JailedUntil.Format(layout)
+}
+
+func PrintDelegationsTable(chains []*model.Chain) {
+	for _, chain := range chains {
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.SetTitle(strings.ToUpper(fmt.Sprintf("delegations of %d accounts on %s", len(chain.Accounts), chain.Name)))
+		t.AppendHeader(table.Row{"Name", "Validator", "Validator Address", "Status", "Commission (%)", "Token", "Shares", "Delegated", "Rewards", "Unbonding", "Redelegating", "Redelegation Lock"})
+
+		inactive, locked := 0, 0
+		now := time.Now()
+		p := message.NewPrinter(language.English)
+		for _, account := range chain.Accounts {
+			if len(account.Delegations) == 0 {
+				continue
+			}
+			for _, d := range account.Delegations {
+				status := d.Status
+				if d.Jailed {
+					status = "jailed"
+				}
+				if !d.Active() {
+					inactive++
+					status = text.Colors{text.FgRed, text.Bold}.Sprint(strings.ToUpper(status))
+				}
+				lock := ""
+				if d.RedelegationLocked(now) {
+					locked++
+					lock = text.FgYellow.Sprint(d.RedelegationLock.UTC().Format(time.DateTime))
+				}
+				t.AppendRow(table.Row{
+					account.Name,
+					d.Moniker,
+					d.ValidatorAddress,
+					status,
+					p.Sprintf("%.2f", d.CommissionRate),
+					d.DisplayName,
+					FormatAmount(d.Shares.TruncateInt(), d.Exponent),
+					FilterZeroAmount(d.Amount, d.Exponent),
+					FilterZeroAmount(d.Rewards, d.Exponent),
+					FilterZeroAmount(d.Unbonding, d.Exponent),
+					FilterZeroAmount(d.Redelegating, d.Exponent),
+					lock,
+				})
+			}
+			t.AppendSeparator()
+		}
+		if inactive > 0 || locked > 0 {
+			t.SetCaption(fmt.Sprintf("%d delegations to jailed or inactive validators, %d cannot be redelegated until their redelegation lock", inactive, locked))
+		}
+
+		t.SetColumnConfigs([]table.ColumnConfig{
+			{Name: "Name", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+			{Name: "Validator", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+			{Name: "Validator Address", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+			{Name: "Status", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+			{Name: "Commission (%)", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+			{Name: "Token", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+			{Name: "Shares", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+			{Name: "Delegated", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+			{Name: "Rewards", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+			{Name: "Unbonding", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+			{Name: "Redelegating", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+			{Name: "Redelegation Lock", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+		})
+		t.Render()
+	}
+}
+
+func PrintRedelegationsTable(chains []*model.Chain) {
+	for _, chain := range chains {
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.SetTitle(strings.ToUpper(fmt.Sprintf("redelegations of %d accounts on %s", len(chain.Accounts), chain.Name)))
+		t.AppendHeader(table.Row{"Name", "From", "From Address", "To", "To Address", "Creation Height", "Completion Time", "Days Left", "Token", "Amount"})
+
+		entries := 0
+		for _, account := range chain.Accounts {
+			for _, entry := range account.Redelegations {
+				entries++
+				t.AppendRow(table.Row{
+					account.Name,
+					entry.SrcMoniker,
+					entry.SrcValidatorAddress,
+					entry.DstMoniker,
+					entry.DstValidatorAddress,
+					entry.CreationHeight,
+					entry.CompletionTime.UTC().Format(time.DateTime),
+					fmt.Sprintf("%.1f", time.Until(entry.CompletionTime).Hours()/24),
+					entry.DisplayName,
+					FormatAmount(entry.Amount, entry.Exponent),
+				})
+			}
+		}
+		t.SetCaption(fmt.Sprintf("%d redelegation entries, nothing can be redelegated from their destination validator until they complete", entries))
+
+		t.SetColumnConfigs([]table.ColumnConfig{
+			{Name: "Name", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+			{Name: "From", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+			{Name: "From Address", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+			{Name: "To", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+			{Name: "To Address", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+			{Name: "Creation Height", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+			{Name: "Completion Time", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+			{Name: "Days Left", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+			{Name: "Token", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+			{Name: "Amount", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+		})
+		t.Render()
+	}
+}
+
+func PrintUnbondingTable(chains []*model.Chain, period string) {
+	for _, chain := range chains {
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.SetTitle(strings.ToUpper(fmt.Sprintf("unbonding of %d accounts on %s", len(chain.Accounts), chain.Name)))
+		t.AppendHeader(table.Row{"Name", "Validator", "Validator Address", "Creation Height", "Completion Time", "Days Left", "Token", "Amount"})
+
+		entries := 0
+		for _, account := range chain.Accounts {
+			for _, entry := range account.Unbondings {
+				entries++
+				t.AppendRow(table.Row{
+					account.Name,
+					entry.Moniker,
+					entry.ValidatorAddress,
+					entry.CreationHeight,
+					entry.CompletionTime.UTC().Format(time.DateTime),
+					fmt.Sprintf("%.1f", time.Until(entry.CompletionTime).Hours()/24),
+					entry.DisplayName,
+					FormatAmount(entry.Amount, entry.Exponent),
+				})
+			}
+		}
+		t.SetCaption(fmt.Sprintf("%d unbonding entries", entries))
+
+		t.SetColumnConfigs([]table.ColumnConfig{
+			{Name: "Name", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+			{Name: "Validator", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+			{Name: "Validator Address", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+			{Name: "Creation Height", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+			{Name: "Completion Time", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+			{Name: "Days Left", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+			{Name: "Token", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+			{Name: "Amount", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+		})
+		t.Render()
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetTitle(strings.ToUpper(fmt.Sprintf("tokens becoming liquid per %s", period)))
+	t.AppendHeader(table.Row{"Chain", "Token", strings.ToUpper(period[:1]) + period[1:], "Entries", "Amount", "Cumulative"})
+
+	cumulative := make(map[string]sdkmath.Int)
+	for _, row := range UnbondingSchedule(chains, period) {
+		key := row.ChainId + "/" + row.DisplayName
+		if _, ok := cumulative[key]; !ok {
+			cumulative[key] = sdkmath.ZeroInt()
+		}
+		cumulative[key] = cumulative[key].Add(row.Amount)
+
+		t.AppendRow(table.Row{
+			row.ChainId,
+			row.DisplayName,
+			PeriodLabel(row.Start, period),
+			row.Entries,
+			FormatAmount(row.Amount, row.Exponent),
+			FormatAmount(cumulative[key], row.Exponent),
+		})
+	}
+
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Name: "Chain", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+		{Name: "Token", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+		{Name: "Entries", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+		{Name: "Amount", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+		{Name: "Cumulative", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+	})
+	t.Render()
+}
+
+// PrintVestingTable shows the vested and locked amounts of vesting accounts at their block time and at every
+// date, followed by their upcoming unlocks
+func PrintVestingTable(chains []*model.Chain, dates []time.Time) {
+	for _, chain := range chains {
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.SetTitle(strings.ToUpper(fmt.Sprintf("vesting accounts on %s", chain.Name)))
+		header := table.Row{"Name", "Type", "Start", "End", "Token", "Original", "Vested", "Locked"}
+		for _, date := range dates {
+			header = append(header, "Locked "+date.UTC().Format(time.DateOnly))
+		}
+		t.AppendHeader(header)
+
+		accounts := 0
+		for _, account := range chain.Accounts {
+			schedule := account.Vesting
+			if schedule == nil {
+				continue
+			}
+			accounts++
+			for _, denom := range schedule.SortedDenoms() {
+				info := schedule.Denoms[denom]
+				row := table.Row{
+					account.Name,
+					schedule.Type,
+					formatVestingTime(schedule.StartTime, time.DateTime),
+					formatVestingTime(schedule.EndTime, time.DateTime),
+					info.DisplayName,
+					FormatAmount(schedule.OriginalVesting[denom], info.Exponent),
+					FormatAmount(schedule.Vested(denom, account.BlockTime), info.Exponent),
+					FormatAmount(schedule.Locked(denom, account.BlockTime), info.Exponent),
+				}
+				for _, date := range dates {
+					row = append(row, FormatAmount(schedule.Locked(denom, date), info.Exponent))
+				}
+				t.AppendRow(row)
+			}
+		}
+		t.SetCaption(fmt.Sprintf("%d of %d accounts are vesting accounts", accounts, len(chain.Accounts)))
+
+		configs := []table.ColumnConfig{
+			{Name: "Name", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+			{Name: "Type", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+			{Name: "Token", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+			{Name: "Original", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+			{Name: "Vested", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+			{Name: "Locked", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+		}
+		for _, date := range dates {
+			configs = append(configs, table.ColumnConfig{Name: "Locked " + date.UTC().Format(time.DateOnly), Align: text.AlignRight, AlignHeader: text.AlignCenter})
+		}
+		t.SetColumnConfigs(configs)
+		t.Render()
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetTitle("UPCOMING UNLOCKS")
+	t.AppendHeader(table.Row{"Chain", "Name", "Unlock Time", "Token", "Amount", "Locked After"})
+	for _, chain := range chains {
+		for _, account := range chain.Accounts {
+			if account.Vesting == nil {
+				continue
+			}
+			for _, unlock := range account.Vesting.Calendar(account.BlockTime) {
+				for _, denom := range account.Vesting.SortedDenoms() {
+					amount, ok := unlock.Amount[denom]
+					if !ok {
+						continue
+					}
+					info := account.Vesting.Denoms[denom]
+					t.AppendRow(table.Row{
+						chain.Id,
+						account.Name,
+						unlock.Time.UTC().Format(time.DateTime),
+						info.DisplayName,
+						FormatAmount(amount, info.Exponent),
+						FormatAmount(account.Vesting.Locked(denom, unlock.Time), info.Exponent),
+					})
+				}
+			}
+		}
+	}
+
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Name: "Chain", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+		{Name: "Name", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+		{Name: "Unlock Time", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+		{Name: "Token", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+		{Name: "Amount", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+		{Name: "Locked After", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+	})
+	t.Render()
+}
+
+// formatVestingTime formats the start or end of a vesting schedule, permanently locked accounts have neither
+func formatVestingTime(t time.Time, layout string) string {
+	if t.IsZero() || t.Unix() == 0 {
+		return ""
+	}
+	return t.UTC().Format(layout)
+}
 
 func FilterZeroValue(value float64) string {
 	if value > 0.00000 {
@@ -119,3 +567,93 @@ func FilterZeroValue(value float64) string {
 		return ""
 	}
 }
+
+func FilterZeroAmount(amount sdkmath.Int, exponent int) string {
+	if amount.IsPositive() {
+		return FormatAmount(amount, exponent)
+	} else {
+		return ""
+	}
+}
+
+// FormatAmount converts an amount of base units to display units without losing precision
+func FormatAmount(amount sdkmath.Int, exponent int) string {
+	if amount.IsNil() {
+		amount = sdkmath.ZeroInt()
+	}
+
+	digits := amount.Abs().String()
+	sign := ""
+	if amount.IsNegative() {
+		sign = "-"
+	}
+
+	if exponent <= 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+func PrintProposalsTable(proposals []*model.GovProposal, deadline time.Duration) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetTitle(strings.ToUpper("Governance - Proposals in voting period"))
+	t.AppendHeader(table.Row{"Chain", "Proposal", "Title", "Voting End", "Yes (%)", "No (%)", "Veto (%)", "Abstain (%)", "Account", "Voter", "Vote"})
+
+	now := time.Now()
+	urgent := 0
+	for _, proposal := range proposals {
+		endsSoon := proposal.EndsWithin(deadline, now)
+		votingEnd := proposal.VotingEndTime.Format(time.RFC822)
+		if endsSoon && len(proposal.Missing()) > 0 {
+			urgent++
+			votingEnd = text.Colors{text.FgRed, text.Bold}.Sprint(votingEnd)
+		}
+
+		for _, vote := range proposal.Votes {
+			option := vote.Option()
+			if !vote.Voted {
+				option = "NOT VOTED"
+				if endsSoon {
+					option = text.Colors{text.FgRed, text.Bold}.Sprint(option)
+				} else {
+					option = text.FgYellow.Sprint(option)
+				}
+			}
+			t.AppendRow([]interface{}{
+				proposal.Chain.Id,
+				proposal.Id,
+				proposal.Title,
+				votingEnd,
+				fmt.Sprintf("%.2f", proposal.Percent(proposal.Yes)),
+				fmt.Sprintf("%.2f", proposal.Percent(proposal.No)),
+				fmt.Sprintf("%.2f", proposal.Percent(proposal.NoWithVeto)),
+				fmt.Sprintf("%.2f", proposal.Percent(proposal.Abstain)),
+				vote.Name,
+				vote.Voter,
+				option,
+			})
+		}
+		t.AppendSeparator()
+	}
+	t.SetCaption(fmt.Sprintf("Retrieved %d proposals, %d not voted by some accounts and ending within %s", len(proposals), urgent, deadline))
+
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Name: "Chain", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+		{Name: "Proposal", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+		{Name: "Title", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+		{Name: "Voting End", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+		{Name: "Yes (%)", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+		{Name: "No (%)", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+		{Name: "Veto (%)", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+		{Name: "Abstain (%)", Align: text.AlignRight, AlignHeader: text.AlignCenter},
+		{Name: "Account", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+		{Name: "Voter", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+		{Name: "Vote", Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+	})
+	t.Render()
+}
@@ -0,0 +1,42 @@
+package display
+
+import (
+	"testing"
+
+	sdkmath "cosmossdk.io/math"
+)
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		amount   string
+		exponent int
+		want     string
+	}{
+		{"1234567", 6, "1.234567"},
+		{"1", 6, "0.000001"},
+		{"0", 6, "0.000000"},
+		{"-1500000", 6, "-1.500000"},
+		{"42", 0, "42"},
+		// 18 decimal denoms (e.g. aevmos, inj) exceed the precision of a float64
+		{"1000000000000000001", 18, "1.000000000000000001"},
+		{"123456789012345678901234567890", 18, "123456789012.345678901234567890"},
+		{"1", 18, "0.000000000000000001"},
+		{"999999999999999999", 18, "0.999999999999999999"},
+		{"-1", 18, "-0.000000000000000001"},
+	}
+	for _, test := range tests {
+		amount, ok := sdkmath.NewIntFromString(test.amount)
+		if !ok {
+			t.Fatalf("cannot parse %s", test.amount)
+		}
+		if got := FormatAmount(amount, test.exponent); got != test.want {
+			t.Errorf("FormatAmount(%s, %d) = %s, want %s", test.amount, test.exponent, got, test.want)
+		}
+	}
+}
+
+func TestFormatAmountNil(t *testing.T) {
+	if got := FormatAmount(sdkmath.Int{}, 18); got != "0.000000000000000000" {
+		t.Errorf("FormatAmount(nil, 18) = %s, want 0.000000000000000000", got)
+	}
+}
@@ -0,0 +1,186 @@
+package display
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/informalsystems/stakooler/client/cosmos/model"
+	"github.com/informalsystems/stakooler/client/zabbix"
+
+	sdkmath "cosmossdk.io/math"
+	"github.com/rs/zerolog/log"
+)
+
+// low-level discovery rule keys
+const (
+	ZbxChainDiscoveryKey     = "stakooler.chain.discovery"
+	ZbxTokenDiscoveryKey     = "stakooler.token.discovery"
+	ZbxAccountDiscoveryKey   = "stakooler.account.discovery"
+	ZbxValidatorDiscoveryKey = "stakooler.validator.discovery"
+)
+
+// ZbxSendChainDiscovery sends the low-level discovery of the chains ({#CHAIN}, {#CHAIN_ID})
+func ZbxSendChainDiscovery(sender *zabbix.Sender, chains []*model.Chain) error {
+	var rows []map[string]string
+	for _, chain := range chains {
+		rows = append(rows, map[string]string{"{#CHAIN}": chain.Name, "{#CHAIN_ID}": chain.Id})
+	}
+
+	discovery, err := zabbix.Discovery(rows)
+	if err != nil {
+		return err
+	}
+	return zbxSend(sender, []zabbix.Item{sender.Item(ZbxChainDiscoveryKey, discovery, time.Now())})
+}
+
+// ZbxAccountDetails sends the discovery of the account tokens ({#CHAIN_ID}, {#ACCOUNT}, {#ADDRESS}, {#DENOM},
+// {#DISPLAY_NAME}) and of the account totals ({#CHAIN_ID}, {#ACCOUNT}, {#CURRENCY}), followed by their values and
+// the block height the balances were queried at
+func ZbxAccountDetails(sender *zabbix.Sender, chains []*model.Chain, currencies []string) error {
+	now := time.Now()
+
+	var tokenRows, accountRows []map[string]string
+	var items []zabbix.Item
+	for _, chain := range chains {
+		height := ""
+		for _, acct := range chain.Accounts {
+			if acct.BlockHeight == "" {
+				continue
+			}
+			height = acct.BlockHeight
+
+			for _, token := range acct.SortedTokens() {
+				// items are keyed on the denom, display names of different denoms can be the same
+				tokenRows = append(tokenRows, map[string]string{
+					"{#CHAIN}":        chain.Name,
+					"{#CHAIN_ID}":     chain.Id,
+					"{#ACCOUNT}":      acct.Name,
+					"{#ADDRESS}":      acct.Address,
+					"{#DENOM}":        token.Denom,
+					"{#DISPLAY_NAME}": token.DisplayName,
+				})
+
+				total := token.Total()
+				amounts := []struct {
+					kind   string
+					amount sdkmath.Int
+				}{
+					{"bank", token.Balances.Bank},
+					{"spendable", token.Balances.Spendable},
+					{"locked", token.Balances.Locked},
+					{"rewards", token.Balances.Rewards},
+					{"delegated", token.Balances.Delegated},
+					{"unbonding", token.Balances.Unbonding},
+					{"redelegating", token.Balances.Redelegating},
+					{"commission", token.Balances.Commission},
+					{"total", total},
+				}
+				for _, a := range amounts {
+					key := zabbix.Key("stakooler.token", chain.Id, acct.Name, token.Denom, a.kind)
+					items = append(items, sender.Item(key, FormatAmount(a.amount, token.Exponent), acct.BlockTime))
+				}
+			}
+
+			for _, currency := range currencies {
+				accountRows = append(accountRows, map[string]string{
+					"{#CHAIN}":    chain.Name,
+					"{#CHAIN_ID}": chain.Id,
+					"{#ACCOUNT}":  acct.Name,
+					"{#ADDRESS}":  acct.Address,
+					"{#CURRENCY}": currency,
+				})
+
+				key := zabbix.Key("stakooler.account.value", chain.Id, acct.Name, currency)
+				items = append(items, sender.Item(key, fmt.Sprintf("%f", acct.Totals[currency]), acct.BlockTime))
+			}
+		}
+
+		if height != "" {
+			items = append(items, sender.Item(zabbix.Key("stakooler.chain.height", chain.Id), height, now))
+		}
+	}
+
+	tokenDiscovery, err := zabbix.Discovery(tokenRows)
+	if err != nil {
+		return err
+	}
+	accountDiscovery, err := zabbix.Discovery(accountRows)
+	if err != nil {
+		return err
+	}
+
+	// discovery goes first so the items exist when their values arrive
+	discovery := []zabbix.Item{
+		sender.Item(ZbxTokenDiscoveryKey, tokenDiscovery, now),
+		sender.Item(ZbxAccountDiscoveryKey, accountDiscovery, now),
+	}
+	if err = zbxSend(sender, discovery); err != nil {
+		return err
+	}
+	return zbxSend(sender, items)
+}
+
+// ZbxValidatorStats sends the discovery of the validators ({#CHAIN_ID}, {#MONIKER}, {#VALOPER}) followed by
+// their statistics
+func ZbxValidatorStats(sender *zabbix.Sender, validators *model.ValidatorList) error {
+	now := time.Now()
+
+	var rows []map[string]string
+	var items []zabbix.Item
+	for _, validator := range validators.Entries {
+		rows = append(rows, map[string]string{
+			"{#CHAIN}":    validator.Chain.Name,
+			"{#CHAIN_ID}": validator.Chain.Id,
+			"{#MONIKER}":  validator.Moniker,
+			"{#VALOPER}":  validator.ValoperAddress,
+		})
+
+		stats := []struct {
+			name  string
+			value string
+		}{
+			{"voting_power", strconv.FormatInt(validator.VotingPower, 10)},
+			{"voting_percent", fmt.Sprintf("%f", validator.VotingPercent)},
+			{"ranking", strconv.Itoa(validator.Ranking)},
+			{"commission", fmt.Sprintf("%f", validator.Commission)},
+			{"validators", validator.NumValidators},
+			{"delegators", validator.NumDelegators},
+			{"unbondings", strconv.FormatInt(validator.Unbondings, 10)},
+		}
+		for _, stat := range stats {
+			key := zabbix.Key("stakooler.validator", validator.Chain.Id, validator.ValoperAddress, stat.name)
+			items = append(items, sender.Item(key, stat.value, validator.BlockTime))
+		}
+	}
+
+	discovery, err := zabbix.Discovery(rows)
+	if err != nil {
+		return err
+	}
+	if err = zbxSend(sender, []zabbix.Item{sender.Item(ZbxValidatorDiscoveryKey, discovery, now)}); err != nil {
+		return err
+	}
+	return zbxSend(sender, items)
+}
+
+// zbxSend sends the items and warns about the ones the trapper did not process, usually items
+// not created yet because the discovery has not been processed
+func zbxSend(sender *zabbix.Sender, items []zabbix.Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	response, err := sender.Send(items)
+	if err != nil {
+		return errors.New(fmt.Sprintf("sending %d items to zabbix: %s", len(items), err))
+	}
+
+	if failed := response.Failed(); failed > 0 {
+		log.Warn().Msg(fmt.Sprintf("zabbix did not process %d of %d items (%s), check the discovery rules and host %s", failed, len(items), response.Info, sender.Host))
+	} else {
+		log.Info().Msg(fmt.Sprintf("zabbix processed %d items", len(items)))
+	}
+	return nil
+}
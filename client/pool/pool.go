@@ -0,0 +1,32 @@
+package pool
+
+import "sync"
+
+// Run calls fn for every index in [0, n) using at most workers goroutines and returns once all calls are done.
+// Callers store results by index so the output order does not depend on the order in which calls finish
+func Run(n int, workers int, fn func(i int)) {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
@@ -0,0 +1,33 @@
+package zabbix
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Key formats an item key with its parameters, e.g. Key("stakooler.token", "cosmoshub-4", "bank") returns
+// stakooler.token["cosmoshub-4","bank"]. Parameters are always quoted so names with commas or brackets are kept whole
+func Key(name string, params ...string) string {
+	if len(params) == 0 {
+		return name
+	}
+
+	quoted := make([]string, len(params))
+	for i, param := range params {
+		quoted[i] = `"` + strings.ReplaceAll(param, `"`, `\"`) + `"`
+	}
+	return name + "[" + strings.Join(quoted, ",") + "]"
+}
+
+// Discovery returns the value of a low-level discovery item, every row maps LLD macros (e.g. {#CHAIN}) to values
+func Discovery(rows []map[string]string) (string, error) {
+	if rows == nil {
+		rows = []map[string]string{}
+	}
+
+	value, err := json.Marshal(rows)
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
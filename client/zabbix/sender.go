@@ -0,0 +1,151 @@
+package zabbix
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultPort is the port of the Zabbix trapper
+	DefaultPort = 10051
+
+	defaultTimeout = 10 * time.Second
+	// maximum response size accepted from the trapper
+	maxResponseSize = 1 << 20
+)
+
+// header of every Zabbix protocol packet, followed by the data length and a reserved field
+var protocolHeader = []byte{'Z', 'B', 'X', 'D', 0x01}
+
+// Sender sends item values to a Zabbix server or proxy trapper using the Zabbix sender protocol
+type Sender struct {
+	Server string
+	Port   int
+	// Host is the name of the Zabbix host items are reported for
+	Host    string
+	Timeout time.Duration
+}
+
+// Item is a single trapper item value for a host
+type Item struct {
+	Host  string `json:"host"`
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Clock int64  `json:"clock,omitempty"`
+}
+
+// Response is the trapper answer to a sender request
+type Response struct {
+	Response string `json:"response"`
+	Info     string `json:"info"`
+}
+
+type senderRequest struct {
+	Request string `json:"request"`
+	Data    []Item `json:"data"`
+	Clock   int64  `json:"clock"`
+}
+
+func NewSender(server string, port int, host string) *Sender {
+	return &Sender{Server: server, Port: port, Host: host, Timeout: defaultTimeout}
+}
+
+// Item returns an item value for the sender host
+func (s *Sender) Item(key string, value string, clock time.Time) Item {
+	return Item{Host: s.Host, Key: key, Value: value, Clock: clock.Unix()}
+}
+
+// Send sends the items in a single request. An error is returned when the trapper cannot be reached or
+// rejects the request, items the trapper did not accept are reported by Response.Failed
+func (s *Sender) Send(items []Item) (*Response, error) {
+	payload, err := json.Marshal(senderRequest{Request: "sender data", Data: items, Clock: time.Now().Unix()})
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(s.Server, strconv.Itoa(s.Port)), s.Timeout)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("connect to zabbix trapper: %s", err))
+	}
+	defer conn.Close()
+
+	if err = conn.SetDeadline(time.Now().Add(s.Timeout)); err != nil {
+		return nil, err
+	}
+
+	if _, err = conn.Write(pack(payload)); err != nil {
+		return nil, errors.New(fmt.Sprintf("send to zabbix trapper: %s", err))
+	}
+
+	body, err := unpack(conn)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("read zabbix trapper response: %s", err))
+	}
+
+	var response Response
+	if err = json.Unmarshal(body, &response); err != nil {
+		return nil, errors.New(fmt.Sprintf("cannot parse zabbix trapper response %q: %s", body, err))
+	}
+	if response.Response != "success" {
+		return &response, errors.New(fmt.Sprintf("zabbix trapper rejected the request: %s", response.Info))
+	}
+	return &response, nil
+}
+
+// Failed returns the number of items the trapper did not process, as reported in the response info
+// (e.g. "processed: 2; failed: 1; total: 3; seconds spent: 0.000055")
+func (r *Response) Failed() int {
+	for _, field := range strings.Split(r.Info, ";") {
+		name, value, found := strings.Cut(strings.TrimSpace(field), ":")
+		if found && name == "failed" {
+			failed, err := strconv.Atoi(strings.TrimSpace(value))
+			if err == nil {
+				return failed
+			}
+		}
+	}
+	return 0
+}
+
+// pack prepends the protocol header and the data length to the payload
+func pack(payload []byte) []byte {
+	packet := make([]byte, 0, len(protocolHeader)+8+len(payload))
+	packet = append(packet, protocolHeader...)
+	packet = binary.LittleEndian.AppendUint32(packet, uint32(len(payload)))
+	// reserved
+	packet = binary.LittleEndian.AppendUint32(packet, 0)
+	return append(packet, payload...)
+}
+
+// unpack reads a packet and returns its payload
+func unpack(r io.Reader) ([]byte, error) {
+	header := make([]byte, len(protocolHeader)+8)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header[:4], protocolHeader[:4]) {
+		return nil, errors.New(fmt.Sprintf("invalid protocol header %q", header[:5]))
+	}
+	if header[4]&0x02 != 0 {
+		return nil, errors.New("compressed responses are not supported")
+	}
+
+	length := binary.LittleEndian.Uint32(header[5:9])
+	if length > maxResponseSize {
+		return nil, errors.New(fmt.Sprintf("response too large (%d bytes)", length))
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
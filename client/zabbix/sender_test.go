@@ -0,0 +1,178 @@
+package zabbix
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// fakeTrapper accepts a single connection on a local port, returns the payload it received on the channel and
+// answers with response
+func fakeTrapper(t *testing.T, response string) (int, <-chan []byte) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan []byte, 1)
+	go func() {
+		defer close(received)
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		header := make([]byte, 13)
+		if _, err = io.ReadFull(conn, header); err != nil {
+			t.Errorf("read header: %s", err)
+			return
+		}
+		if !bytes.Equal(header[:5], []byte("ZBXD\x01")) {
+			t.Errorf("header = %q, want ZBXD\\x01", header[:5])
+		}
+		if reserved := binary.LittleEndian.Uint32(header[9:13]); reserved != 0 {
+			t.Errorf("reserved = %d, want 0", reserved)
+		}
+		payload := make([]byte, binary.LittleEndian.Uint32(header[5:9]))
+		if _, err = io.ReadFull(conn, payload); err != nil {
+			t.Errorf("read payload of %d bytes: %s", len(payload), err)
+			return
+		}
+		received <- payload
+
+		answer := binary.LittleEndian.AppendUint64([]byte("ZBXD\x01"), uint64(len(response)))
+		conn.Write(append(answer, response...))
+	}()
+
+	return listener.Addr().(*net.TCPAddr).Port, received
+}
+
+func TestSend(t *testing.T) {
+	port, received := fakeTrapper(t, `{"response":"success","info":"processed: 2; failed: 1; total: 3; seconds spent: 0.000055"}`)
+
+	discovery, err := Discovery([]map[string]string{{"{#CHAIN}": "cosmoshub-4", "{#ACCOUNT}": "treasury"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := NewSender("127.0.0.1", port, "stakooler")
+	clock := time.Unix(1700000000, 0)
+	items := []Item{
+		sender.Item("stakooler.chain.discovery", discovery, clock),
+		sender.Item(Key("stakooler.token", "cosmoshub-4", "treasury", "ATOM", "bank"), "12.5", clock),
+		sender.Item(Key("stakooler.token", `say "hi"`), "1", clock),
+	}
+
+	response, err := sender.Send(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Failed() != 1 {
+		t.Errorf("Failed() = %d, want 1", response.Failed())
+	}
+
+	var request struct {
+		Request string `json:"request"`
+		Data    []Item `json:"data"`
+	}
+	if err = json.Unmarshal(<-received, &request); err != nil {
+		t.Fatal(err)
+	}
+	if request.Request != "sender data" {
+		t.Errorf("request = %q, want sender data", request.Request)
+	}
+	want := []Item{
+		{Host: "stakooler", Key: "stakooler.chain.discovery", Value: `[{"{#ACCOUNT}":"treasury","{#CHAIN}":"cosmoshub-4"}]`, Clock: 1700000000},
+		{Host: "stakooler", Key: `stakooler.token["cosmoshub-4","treasury","ATOM","bank"]`, Value: "12.5", Clock: 1700000000},
+		{Host: "stakooler", Key: `stakooler.token["say \"hi\""]`, Value: "1", Clock: 1700000000},
+	}
+	if len(request.Data) != len(want) {
+		t.Fatalf("sent %d items, want %d", len(request.Data), len(want))
+	}
+	for i := range want {
+		if request.Data[i] != want[i] {
+			t.Errorf("item %d = %+v, want %+v", i, request.Data[i], want[i])
+		}
+	}
+}
+
+func TestSendRejected(t *testing.T) {
+	port, _ := fakeTrapper(t, `{"response":"failed","info":"invalid request"}`)
+
+	response, err := NewSender("127.0.0.1", port, "stakooler").Send([]Item{{Host: "stakooler", Key: "k", Value: "v"}})
+	if err == nil {
+		t.Fatal("Send() succeeded, want an error")
+	}
+	if response == nil || response.Info != "invalid request" {
+		t.Errorf("response = %+v, want the trapper info", response)
+	}
+}
+
+func TestSendUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	sender := NewSender("127.0.0.1", port, "stakooler")
+	sender.Timeout = time.Second
+	if _, err = sender.Send(nil); err == nil {
+		t.Errorf("Send() to closed port %s succeeded, want an error", strconv.Itoa(port))
+	}
+}
+
+func TestFailed(t *testing.T) {
+	tests := []struct {
+		info string
+		want int
+	}{
+		{"processed: 2; failed: 1; total: 3; seconds spent: 0.000055", 1},
+		{"processed: 3; failed: 0; total: 3; seconds spent: 0.000055", 0},
+		{"processed: 0; failed: 12; total: 12; seconds spent: 0.1", 12},
+		{"failed:4", 4},
+		{"", 0},
+		{"failed: many", 0},
+	}
+	for _, test := range tests {
+		response := Response{Response: "success", Info: test.info}
+		if got := response.Failed(); got != test.want {
+			t.Errorf("Failed() of %q = %d, want %d", test.info, got, test.want)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		params []string
+		want   string
+	}{
+		{"stakooler.chain.discovery", nil, "stakooler.chain.discovery"},
+		{"stakooler.token", []string{"cosmoshub-4", "bank"}, `stakooler.token["cosmoshub-4","bank"]`},
+		{"stakooler.token", []string{"a,b", "[c]"}, `stakooler.token["a,b","[c]"]`},
+	}
+	for _, test := range tests {
+		if got := Key(test.name, test.params...); got != test.want {
+			t.Errorf("Key(%q, %q) = %s, want %s", test.name, test.params, got, test.want)
+		}
+	}
+}
+
+func TestDiscoveryEmpty(t *testing.T) {
+	value, err := Discovery(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != "[]" {
+		t.Errorf("Discovery(nil) = %s, want []", value)
+	}
+}
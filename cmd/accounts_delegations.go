@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/informalsystems/stakooler/client/cosmos/api"
+	"github.com/informalsystems/stakooler/client/cosmos/model"
+	"github.com/informalsystems/stakooler/client/display"
+	"github.com/informalsystems/stakooler/config"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagDelegationsOutput *string
+	flagInactiveOnly      *bool
+)
+
+// represents the 'accounts delegations' command
+var accountDelegationsCmd = &cobra.Command{
+	Use:   "delegations",
+	Short: "Shows the delegations of accounts per validator",
+	Long: `This command shows the delegations of the configured accounts broken down per validator. For example:
+
+It shows the validator moniker, status, commission rate, delegated amount, shares, pending rewards and
+unbonding tokens, so delegations to jailed or inactive validators stand out`,
+	Run: func(cmd *cobra.Command, args []string) {
+		output := *flagDelegationsOutput
+		if output != outputTable && output != outputCsv {
+			log.Fatal().Msg(fmt.Sprintf("unknown output format %s, use table or csv", output))
+		}
+
+		rawAcctData, err := config.ReadAccountData(flagConfigPath, flagProfile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("error reading account data file")
+		}
+
+		httpClient := api.NewHttpClient(flagEndpointConcurrency)
+		// delegations are not valued, prices are not needed
+		chains := config.ParseAccountsConfig(rawAcctData, nil, flagConcurrency, httpClient)
+		fetchAccountBalances(chains, snapshot{}, httpClient, output == outputTable, false)
+
+		if *flagInactiveOnly {
+			filterInactiveDelegations(chains)
+		}
+
+		if output == outputCsv {
+			err = writeReport("delegations", "csv", true, func(w io.Writer) error {
+				return display.WriteDelegationsCSV(w, chains)
+			})
+			if err != nil {
+				log.Fatal().Err(err).Msg("error writing report")
+			}
+		} else {
+			display.PrintDelegationsTable(chains)
+		}
+	},
+}
+
+// filterInactiveDelegations only keeps the delegations to jailed or inactive validators
+func filterInactiveDelegations(chains []*model.Chain) {
+	for _, chain := range chains {
+		for _, acct := range chain.Accounts {
+			var inactive []*model.Delegation
+			for _, delegation := range acct.Delegations {
+				if !delegation.Active() {
+					inactive = append(inactive, delegation)
+				}
+			}
+			acct.Delegations = inactive
+		}
+	}
+}
+
+func init() {
+	flagDelegationsOutput = accountDelegationsCmd.Flags().StringP("output", "o", outputTable, "output format: table or csv")
+	flagInactiveOnly = accountDelegationsCmd.Flags().Bool("inactive", false, "only show delegations to jailed or inactive validators")
+	accountsCmd.AddCommand(accountDelegationsCmd)
+}
@@ -2,21 +2,62 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/informalsystems/stakooler/client/cosmos/api"
+	"github.com/informalsystems/stakooler/client/cosmos/model"
 	"github.com/informalsystems/stakooler/client/display"
+	"github.com/informalsystems/stakooler/client/pool"
+	"github.com/informalsystems/stakooler/client/zabbix"
 	"github.com/informalsystems/stakooler/config"
 
 	"github.com/rs/zerolog/log"
-	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
 )
 
+// output formats of the reports
+const (
+	outputTable = "table"
+	outputCsv   = "csv"
+	outputJson  = "json"
+	outputYaml  = "yaml"
+)
+
 var (
 	flagCsv            *bool
+	flagOutput         *string
 	flagZbxAcctDetails *bool
+	flagHeight         *int64
+	flagAt             *string
+	flagWithdraw       *bool
 )
 
+// snapshot selects the block balances are queried at, the latest block when empty
+type snapshot struct {
+	height int64
+	at     time.Time
+}
+
+func (s snapshot) historical() bool {
+	return s.height > 0 || !s.at.IsZero()
+}
+
+// report returns the snapshot recorded in the json and yaml reports, nil for the latest block
+func (s snapshot) report() *display.SnapshotReport {
+	if !s.historical() {
+		return nil
+	}
+	snapshot := &display.SnapshotReport{Height: s.height}
+	if !s.at.IsZero() {
+		at := s.at.UTC()
+		snapshot.Time = &at
+	}
+	return snapshot
+}
+
 // represents the 'accounts details' command
 var accountDetailsCmd = &cobra.Command{
 	Use:   "details",
@@ -25,62 +66,196 @@ var accountDetailsCmd = &cobra.Command{
 
 It shows tokens balance, rewards, delegation and unbonding values per account`,
 	Run: func(cmd *cobra.Command, args []string) {
-		barEnabled := !*flagCsv
-		rawAcctData, err := config.ReadAccountData("")
+		output := *flagOutput
+		if *flagCsv {
+			output = outputCsv
+		}
+		switch output {
+		case outputTable, outputCsv, outputJson, outputYaml:
+		default:
+			log.Fatal().Msg(fmt.Sprintf("unknown output format %s, use table, csv, json or yaml", output))
+		}
+
+		barEnabled := output == outputTable && !*flagZbxAcctDetails
+		at := snapshot{height: *flagHeight}
+		if *flagAt != "" {
+			parsed, err := time.Parse(time.RFC3339, *flagAt)
+			if err != nil {
+				log.Fatal().Err(err).Msg("invalid --at time, use RFC3339 (e.g. 2025-12-31T23:59:59Z)")
+			}
+			at.at = parsed
+		}
+
+		rawAcctData, err := config.ReadAccountData(flagConfigPath, flagProfile)
 		if err != nil {
 			log.Fatal().Err(err).Msg("error reading account data file")
 		}
 
-		var bar *progressbar.ProgressBar
-
-		httpClient := api.NewHttpClient()
-		chains := config.ParseAccountsConfig(rawAcctData, httpClient)
+		var sender *zabbix.Sender
+		if *flagZbxAcctDetails {
+			if sender, err = config.ParseZabbixConfig(rawAcctData); err != nil {
+				log.Fatal().Err(err).Msg("zabbix output requested, missing or incorrect zabbix configuration")
+			}
+		}
 
-		if barEnabled {
-			// Progress bar
-			// iterations are the api calls number times the number of accounts
-			totalIterations := len(chains)
-			bar = progressbar.NewOptions(totalIterations, progressbar.OptionEnableColorCodes(true), progressbar.OptionShowBytes(false), progressbar.OptionSetWidth(25), progressbar.OptionUseANSICodes(false), progressbar.OptionClearOnFinish(), progressbar.OptionSetPredictTime(false), progressbar.OptionSetTheme(progressbar.Theme{
-				Saucer:        "▪︎[reset]",
-				SaucerHead:    ">[reset]",
-				SaucerPadding: ".",
-				BarStart:      "[",
-				BarEnd:        "]",
-			}))
-		} else {
-			bar = progressbar.New(0)
+		httpClient := api.NewHttpClient(flagEndpointConcurrency)
+		oracle, err := config.ParsePriceOracle(rawAcctData, httpClient)
+		if err != nil {
+			log.Fatal().Err(err).Msg("error configuring price providers")
+		}
+		if at.historical() {
+			// price providers return current prices, except a price file which may hold the snapshot prices
+			log.Warn().Msg("balances are queried at the requested snapshot but fiat values use the prices of the price providers, which are current ones unless they come from a price file")
+			oracle.KeepCacheInMemory()
 		}
+		chains := config.ParseAccountsConfig(rawAcctData, oracle, flagConcurrency, httpClient)
 
+		fetchAccountBalances(chains, at, httpClient, barEnabled, *flagWithdraw)
 		for _, chain := range chains {
-			blockInfo := api.BlockResponse{}
-			if err := blockInfo.GetLatestBlock(chain.RestEndpoint, httpClient); err != nil {
-				log.Error().Err(err).Msg(fmt.Sprintf("failed to get latest block, skipping chain %s", chain.Id))
+			for _, acct := range chain.ExternalWithdrawAccounts() {
+				log.Warn().Msg(fmt.Sprintf("rewards of %s on %s are withdrawn to %s, which is not a configured account", acct.Name, chain.Id, acct.WithdrawAddress))
 			}
+		}
+		if err = oracle.SaveCache(); err != nil {
+			log.Error().Err(err).Msg("failed saving price cache")
+		}
 
-			if barEnabled {
-				bar.Describe(fmt.Sprintf("Getting chain %s details", chain.Id))
+		if sender != nil {
+			if err = display.ZbxSendChainDiscovery(sender, chains); err == nil {
+				err = display.ZbxAccountDetails(sender, chains, oracle.Currencies)
 			}
-
-			if err = chain.FetchAccountBalances(blockInfo, httpClient); err != nil {
-				log.Error().Err(err).Msg(fmt.Sprintf("failed fetching accounts for %s", chain.Name))
+			if err != nil {
+				log.Fatal().Err(err).Msg("error sending to zabbix")
 			}
-			bar.Add(1)
+			return
 		}
 
-		if err := bar.Finish(); err != nil {
-			log.Error().Err(err).Msg(fmt.Sprintf("failed to finish bar"))
+		switch output {
+		case outputCsv:
+			err = writeReports(
+				report{name: "accounts_details", ext: "csv", toStdout: true, write: func(w io.Writer) error {
+					return display.WriteAccountsCSV(w, chains)
+				}},
+				report{name: "dollar_value_report", ext: "csv", write: func(w io.Writer) error {
+					return display.WriteDollarValueReport(w, chains, oracle.Currencies)
+				}},
+			)
+		case outputJson:
+			err = writeReport("accounts_details", "json", true, func(w io.Writer) error {
+				return display.WriteAccountsJSON(w, chains, oracle.Currencies, at.report())
+			})
+		case outputYaml:
+			err = writeReport("accounts_details", "yaml", true, func(w io.Writer) error {
+				return display.WriteAccountsYAML(w, chains, oracle.Currencies, at.report())
+			})
+		default:
+			display.PrintAccountDetailsTable(chains, oracle.Currencies)
 		}
 
-		if *flagCsv {
-			display.WriteAccountsCSV(chains)
-			display.WriteDollarValueReport(chains)
-		} else {
-			display.PrintAccountDetailsTable(chains)
+		if err != nil {
+			log.Fatal().Err(err).Msg("error writing report")
 		}
 	},
 }
 
+// fetchAccountBalances resolves the block of every chain matching the snapshot and then fetches the balances
+// of every account at that block, using a worker pool bounded by the concurrency flag. Results are stored in place
+// so the order is preserved. Withdraw addresses outside the accounts of a chain are fetched at the same block when
+// includeWithdraw is set
+func fetchAccountBalances(chains []*model.Chain, at snapshot, httpClient *http.Client, barEnabled bool, includeWithdraw bool) {
+	type job struct {
+		chain   *model.Chain
+		block   *api.BlockResponse
+		querier api.Querier
+		idx     int
+	}
+
+	blocks := make([]api.BlockResponse, len(chains))
+	queriers := make([]api.Querier, len(chains))
+	pool.Run(len(chains), flagConcurrency, func(i int) {
+		var err error
+		switch {
+		case at.height > 0:
+			err = chains[i].Querier.Block(strconv.FormatInt(at.height, 10), &blocks[i])
+		case !at.at.IsZero():
+			err = blocks[i].GetBlockAt(at.at, chains[i].Querier)
+		default:
+			err = chains[i].Querier.LatestBlock(&blocks[i])
+		}
+
+		if err != nil {
+			log.Error().Err(err).Msg(fmt.Sprintf("failed to get block, skipping chain %s", chains[i].Id))
+			// without the block historical balances would be mixed with the latest ones
+			if at.historical() {
+				return
+			}
+		}
+
+		queriers[i] = chains[i].Querier
+		if at.historical() {
+			// every query of the chain is made at the resolved height so balances are consistent
+			queriers[i] = chains[i].Querier.AtHeight(blocks[i].Block.Header.Height)
+		}
+	})
+
+	var jobs []job
+	for i, chain := range chains {
+		if queriers[i] == nil {
+			continue
+		}
+		for idx := range chain.Accounts {
+			jobs = append(jobs, job{chain: chain, block: &blocks[i], querier: queriers[i], idx: idx})
+		}
+	}
+
+	fetch := func(jobs []job) {
+		// iterations are the number of accounts across all chains
+		bar := newProgressBar(len(jobs), barEnabled)
+		pool.Run(len(jobs), flagConcurrency, func(i int) {
+			j := jobs[i]
+			if barEnabled {
+				bar.Describe(fmt.Sprintf("Getting chain %s details", j.chain.Id))
+			}
+
+			if err := j.chain.FetchAccountBalance(j.idx, *j.block, j.querier, httpClient); err != nil {
+				log.Error().Err(err).Msg(fmt.Sprintf("failed fetching account %s for %s", j.chain.Accounts[j.idx].Name, j.chain.Name))
+			}
+			bar.Add(1)
+		})
+		finishProgressBar(bar)
+	}
+	fetch(jobs)
+	if !includeWithdraw {
+		return
+	}
+
+	var withdrawJobs []job
+	for i, chain := range chains {
+		if queriers[i] == nil {
+			continue
+		}
+		added, err := chain.AddWithdrawAccounts()
+		if err != nil {
+			log.Error().Err(err).Msg(fmt.Sprintf("failed adding the withdraw addresses of %s", chain.Id))
+		}
+		for _, idx := range added {
+			log.Info().Msg(fmt.Sprintf("adding withdraw address %s of %s to the report", chain.Accounts[idx].Address, chain.Id))
+			withdrawJobs = append(withdrawJobs, job{chain: chain, block: &blocks[i], querier: queriers[i], idx: idx})
+		}
+	}
+	if len(withdrawJobs) > 0 {
+		fetch(withdrawJobs)
+	}
+}
+
 func init() {
 	flagCsv = accountDetailsCmd.Flags().BoolP("csv", "c", false, "output the result to a csv format")
+	_ = accountDetailsCmd.Flags().MarkDeprecated("csv", "use --output csv instead")
+	flagOutput = accountDetailsCmd.Flags().StringP("output", "o", outputTable, "output format: table, csv, json or yaml")
+	flagZbxAcctDetails = accountDetailsCmd.Flags().BoolP("zabbix", "z", false, "send the result to the zabbix trapper configured in the account data file")
+	flagHeight = accountDetailsCmd.Flags().Int64("height", 0, "query balances at this block height instead of the latest block")
+	flagAt = accountDetailsCmd.Flags().String("at", "", "query balances at the last block produced at or before this RFC3339 time (e.g. 2025-12-31T23:59:59Z)")
+	flagWithdraw = accountDetailsCmd.Flags().Bool("include-withdraw", false, "add the withdraw addresses that are not configured accounts to the report")
+	accountDetailsCmd.MarkFlagsMutuallyExclusive("height", "at")
 	accountsCmd.AddCommand(accountDetailsCmd)
 }
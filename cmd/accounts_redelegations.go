@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/informalsystems/stakooler/client/cosmos/api"
+	"github.com/informalsystems/stakooler/client/display"
+	"github.com/informalsystems/stakooler/config"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var flagRedelegationsOutput *string
+
+// represents the 'accounts redelegations' command
+var accountRedelegationsCmd = &cobra.Command{
+	Use:   "redelegations",
+	Short: "Shows the redelegations in flight of accounts",
+	Long: `This command lists every redelegation entry of the configured accounts still in flight, sorted by completion time.
+
+It shows the source and destination validators, amount and completion time of each entry. Until an entry completes
+nothing can be redelegated from its destination validator`,
+	Run: func(cmd *cobra.Command, args []string) {
+		output := *flagRedelegationsOutput
+		if output != outputTable && output != outputCsv {
+			log.Fatal().Msg(fmt.Sprintf("unknown output format %s, use table or csv", output))
+		}
+
+		rawAcctData, err := config.ReadAccountData(flagConfigPath, flagProfile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("error reading account data file")
+		}
+
+		httpClient := api.NewHttpClient(flagEndpointConcurrency)
+		chains := config.ParseAccountsConfig(rawAcctData, nil, flagConcurrency, httpClient)
+		fetchAccountBalances(chains, snapshot{}, httpClient, output == outputTable, false)
+
+		if output == outputCsv {
+			err = writeReport("redelegations", "csv", true, func(w io.Writer) error {
+				return display.WriteRedelegationsCSV(w, chains)
+			})
+			if err != nil {
+				log.Fatal().Err(err).Msg("error writing report")
+			}
+		} else {
+			display.PrintRedelegationsTable(chains)
+		}
+	},
+}
+
+func init() {
+	flagRedelegationsOutput = accountRedelegationsCmd.Flags().StringP("output", "o", outputTable, "output format: table or csv")
+	accountsCmd.AddCommand(accountRedelegationsCmd)
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/informalsystems/stakooler/client/cosmos/api"
+	"github.com/informalsystems/stakooler/client/cosmos/model"
+	"github.com/informalsystems/stakooler/client/display"
+	"github.com/informalsystems/stakooler/config"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagUnbondingOutput *string
+	flagUnbondingBy     *string
+	flagWithin          *string
+	flagAggregate       *bool
+)
+
+// represents the 'accounts unbonding' command
+var accountUnbondingCmd = &cobra.Command{
+	Use:   "unbonding",
+	Short: "Shows the unbonding schedule of accounts",
+	Long: `This command lists every unbonding entry of the configured accounts, sorted by completion time. For example:
+
+It shows the validator, amount and completion time of each entry, and the tokens becoming liquid per day or week`,
+	Run: func(cmd *cobra.Command, args []string) {
+		output := *flagUnbondingOutput
+		if output != outputTable && output != outputCsv {
+			log.Fatal().Msg(fmt.Sprintf("unknown output format %s, use table or csv", output))
+		}
+
+		period := *flagUnbondingBy
+		if period != display.PeriodDay && period != display.PeriodWeek {
+			log.Fatal().Msg(fmt.Sprintf("unknown period %s, use day or week", period))
+		}
+
+		var within time.Duration
+		if *flagWithin != "" {
+			var err error
+			if within, err = parseWithin(*flagWithin); err != nil {
+				log.Fatal().Err(err).Msg("invalid --within")
+			}
+		}
+
+		rawAcctData, err := config.ReadAccountData(flagConfigPath, flagProfile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("error reading account data file")
+		}
+
+		httpClient := api.NewHttpClient(flagEndpointConcurrency)
+		chains := config.ParseAccountsConfig(rawAcctData, nil, flagConcurrency, httpClient)
+		fetchAccountBalances(chains, snapshot{}, httpClient, output == outputTable, false)
+
+		if within > 0 {
+			filterUnbondingsBefore(chains, time.Now().Add(within))
+		}
+
+		switch {
+		case output == outputCsv && *flagAggregate:
+			err = writeReport("unbonding_schedule", "csv", true, func(w io.Writer) error {
+				return display.WriteUnbondingScheduleCSV(w, chains, period)
+			})
+		case output == outputCsv:
+			err = writeReport("unbonding", "csv", true, func(w io.Writer) error {
+				return display.WriteUnbondingCSV(w, chains)
+			})
+		default:
+			display.PrintUnbondingTable(chains, period)
+		}
+
+		if err != nil {
+			log.Fatal().Err(err).Msg("error writing report")
+		}
+	},
+}
+
+// parseWithin parses a duration that can also be given in days (7d) or weeks (2w)
+func parseWithin(value string) (time.Duration, error) {
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	for suffix, unit := range units {
+		if count, found := strings.CutSuffix(value, suffix); found {
+			n, err := strconv.ParseFloat(count, 64)
+			if err != nil || n <= 0 {
+				return 0, errors.New(fmt.Sprintf("%s is not a positive number of %s", value, suffix))
+			}
+			return time.Duration(n * float64(unit)), nil
+		}
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err == nil && duration <= 0 {
+		err = errors.New(fmt.Sprintf("%s is not positive", value))
+	}
+	return duration, err
+}
+
+// filterUnbondingsBefore only keeps the unbonding entries completing before the deadline
+func filterUnbondingsBefore(chains []*model.Chain, deadline time.Time) {
+	for _, chain := range chains {
+		for _, acct := range chain.Accounts {
+			var entries []*model.UnbondingEntry
+			for _, entry := range acct.Unbondings {
+				if !entry.CompletionTime.After(deadline) {
+					entries = append(entries, entry)
+				}
+			}
+			acct.Unbondings = entries
+		}
+	}
+}
+
+func init() {
+	flagUnbondingOutput = accountUnbondingCmd.Flags().StringP("output", "o", outputTable, "output format: table or csv")
+	flagUnbondingBy = accountUnbondingCmd.Flags().String("by", display.PeriodDay, "period tokens becoming liquid are summed per: day or week")
+	flagWithin = accountUnbondingCmd.Flags().String("within", "", "only show entries completing within this duration (e.g. 7d, 2w, 36h)")
+	flagAggregate = accountUnbondingCmd.Flags().Bool("aggregate", false, "write the tokens becoming liquid per period instead of the entries in the csv output")
+	accountsCmd.AddCommand(accountUnbondingCmd)
+}
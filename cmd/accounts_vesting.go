@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/informalsystems/stakooler/client/cosmos/api"
+	"github.com/informalsystems/stakooler/client/display"
+	"github.com/informalsystems/stakooler/config"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagVestingOutput *string
+	flagVestingAt     *[]string
+	flagCalendar      *bool
+)
+
+// represents the 'accounts vesting' command
+var accountVestingCmd = &cobra.Command{
+	Use:   "vesting",
+	Short: "Shows the vesting schedule of vesting accounts",
+	Long: `This command shows how much of the original vesting of the configured vesting accounts is vested and
+still locked, now and at the dates given with --at. For example:
+
+stakooler accounts vesting --at 2025-01-01 --at 90d
+
+Continuous, delayed, periodic and permanently locked accounts are supported, other accounts are skipped.
+The upcoming unlocks are listed per vesting period, or per month for continuous vesting accounts`,
+	Run: func(cmd *cobra.Command, args []string) {
+		output := *flagVestingOutput
+		if output != outputTable && output != outputCsv {
+			log.Fatal().Msg(fmt.Sprintf("unknown output format %s, use table or csv", output))
+		}
+
+		dates, err := parseDates(*flagVestingAt, time.Now())
+		if err != nil {
+			log.Fatal().Err(err).Msg("invalid --at")
+		}
+
+		rawAcctData, err := config.ReadAccountData(flagConfigPath, flagProfile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("error reading account data file")
+		}
+
+		httpClient := api.NewHttpClient(flagEndpointConcurrency)
+		chains := config.ParseAccountsConfig(rawAcctData, nil, flagConcurrency, httpClient)
+		fetchAccountBalances(chains, snapshot{}, httpClient, output == outputTable, false)
+
+		switch {
+		case output == outputCsv && *flagCalendar:
+			err = writeReport("vesting_calendar", "csv", true, func(w io.Writer) error {
+				return display.WriteVestingCalendarCSV(w, chains)
+			})
+		case output == outputCsv:
+			err = writeReport("vesting", "csv", true, func(w io.Writer) error {
+				return display.WriteVestingCSV(w, chains, dates)
+			})
+		default:
+			display.PrintVestingTable(chains, dates)
+		}
+
+		if err != nil {
+			log.Fatal().Err(err).Msg("error writing report")
+		}
+	},
+}
+
+// parseDates parses dates (2025-01-01), times (RFC3339) or durations from now (90d, 2w, 36h), in ascending order
+func parseDates(values []string, now time.Time) ([]time.Time, error) {
+	var dates []time.Time
+	for _, value := range values {
+		if date, err := time.ParseInLocation(time.DateOnly, value, time.UTC); err == nil {
+			dates = append(dates, date)
+		} else if date, err = time.Parse(time.RFC3339, value); err == nil {
+			dates = append(dates, date)
+		} else if within, err := parseWithin(value); err == nil {
+			dates = append(dates, now.Add(within))
+		} else {
+			return nil, errors.New(fmt.Sprintf("%s is neither a date, a RFC3339 time nor a duration", value))
+		}
+	}
+
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+	return dates, nil
+}
+
+func init() {
+	flagVestingOutput = accountVestingCmd.Flags().StringP("output", "o", outputTable, "output format: table or csv")
+	flagVestingAt = accountVestingCmd.Flags().StringSlice("at", nil, "also show the amounts at these dates (2025-01-01), times (RFC3339) or durations from now (90d)")
+	flagCalendar = accountVestingCmd.Flags().Bool("calendar", false, "write the upcoming unlocks instead of the amounts in the csv output")
+	accountsCmd.AddCommand(accountVestingCmd)
+}
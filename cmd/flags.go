@@ -1,12 +1,30 @@
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"github.com/informalsystems/stakooler/client/display"
+
+	"github.com/spf13/cobra"
+)
 
 var (
-	flagConfigPath string
+	flagConfigPath          string
+	flagProfile             string
+	flagConcurrency         int
+	flagEndpointConcurrency int
+	flagOutputDir           string
+	flagFileTemplate        string
+	flagForce               bool
 )
 
 // addGlobalFlags defines flags to be used regardless of the command used
 func addGlobalFlags(cmd *cobra.Command) {
-	rootCmd.PersistentFlags().StringVarP(&flagConfigPath, "config", "f", "", "configuration file")
+	cmd.PersistentFlags().StringVarP(&flagConfigPath, "config", "f", "", "configuration file")
+	cmd.PersistentFlags().StringVarP(&flagProfile, "profile", "p", "", "name of the account file to use from ~/.stakooler (e.g. treasury for ~/.stakooler/treasury.json)")
+	cmd.MarkFlagsMutuallyExclusive("config", "profile")
+	cmd.PersistentFlags().IntVar(&flagConcurrency, "concurrency", 8, "maximum number of chains and accounts fetched at the same time")
+	cmd.PersistentFlags().IntVar(&flagEndpointConcurrency, "endpoint-concurrency", 4, "maximum number of requests in flight against a single endpoint (0 for no limit)")
+	cmd.PersistentFlags().StringVar(&flagOutputDir, "output-dir", "", "directory reports are written to instead of stdout (--out is an alias)")
+	cmd.PersistentFlags().StringVar(&flagFileTemplate, "file-template", display.DefaultFileTemplate, "report file name template, supports {report}, {ext}, {date} and {timestamp}")
+	cmd.PersistentFlags().BoolVar(&flagForce, "force", false, "overwrite existing report files")
+	cmd.SetGlobalNormalizationFunc(normalizeOutputFlags)
 }
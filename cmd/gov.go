@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// govCmd represents the gov command
+var govCmd = &cobra.Command{
+	Use:   "gov",
+	Short: "Displays information about governance",
+	Long:  `Displays the governance proposals of the chains and the votes of the configured accounts in a table friendly or csv format`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(govCmd)
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/informalsystems/stakooler/client/cosmos/api"
+	"github.com/informalsystems/stakooler/client/cosmos/model"
+	"github.com/informalsystems/stakooler/client/display"
+	"github.com/informalsystems/stakooler/client/pool"
+	"github.com/informalsystems/stakooler/config"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagProposalsOutput *string
+	flagMissing         *bool
+	flagDeadline        *time.Duration
+)
+
+// represents the 'gov proposals' command
+var govProposalsCmd = &cobra.Command{
+	Use:   "proposals",
+	Short: "Shows the proposals in voting period and the votes of the accounts",
+	Long: `This command lists the proposals in voting period of every chain, with their voting end time, their current
+tally and whether each configured account, which also votes for its validator, has voted and how. For example:
+
+stakooler gov proposals --missing --deadline 48h
+
+With --missing only the proposals and accounts that have not voted are listed. Proposals not voted by some
+accounts and ending within --deadline are highlighted`,
+	Run: func(cmd *cobra.Command, args []string) {
+		output := *flagProposalsOutput
+		if output != outputTable && output != outputCsv {
+			log.Fatal().Msg(fmt.Sprintf("unknown output format %s, use table or csv", output))
+		}
+
+		rawAcctData, err := config.ReadAccountData(flagConfigPath, flagProfile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("error reading account data file")
+		}
+
+		httpClient := api.NewHttpClient(flagEndpointConcurrency)
+		chains := config.ParseAccountsConfig(rawAcctData, nil, flagConcurrency, httpClient)
+
+		proposals := fetchGovProposals(chains, output == outputTable)
+		if *flagMissing {
+			proposals = model.FilterMissingVotes(proposals)
+		}
+
+		if output == outputCsv {
+			err = writeReport("gov_proposals", "csv", true, func(w io.Writer) error {
+				return display.WriteProposalsCSV(w, proposals, *flagDeadline)
+			})
+			if err != nil {
+				log.Fatal().Err(err).Msg("error writing report")
+			}
+		} else {
+			display.PrintProposalsTable(proposals, *flagDeadline)
+		}
+	},
+}
+
+// fetchGovProposals fetches the proposals of every chain using a worker pool bounded by the concurrency flag,
+// keeping the proposals in the same order as the chains
+func fetchGovProposals(chains []*model.Chain, barEnabled bool) []*model.GovProposal {
+	// iterations are the number of chains
+	bar := newProgressBar(len(chains), barEnabled)
+
+	proposals := make([][]*model.GovProposal, len(chains))
+	pool.Run(len(chains), flagConcurrency, func(i int) {
+		if barEnabled {
+			bar.Describe(fmt.Sprintf("Getting proposals for %s", chains[i].Id))
+		}
+
+		var err error
+		if proposals[i], err = chains[i].FetchGovProposals(); err != nil {
+			log.Error().Err(err).Msg(fmt.Sprintf("failed fetching proposals for %s", chains[i].Name))
+		}
+		bar.Add(1)
+	})
+	finishProgressBar(bar)
+
+	var all []*model.GovProposal
+	for _, chainProposals := range proposals {
+		all = append(all, chainProposals...)
+	}
+	return all
+}
+
+func init() {
+	flagProposalsOutput = govProposalsCmd.Flags().StringP("output", "o", outputTable, "output format: table or csv")
+	flagMissing = govProposalsCmd.Flags().Bool("missing", false, "only list the proposals and accounts that have not voted")
+	flagDeadline = govProposalsCmd.Flags().Duration("deadline", 72*time.Hour, "highlight the proposals not voted and ending within this duration")
+	govCmd.AddCommand(govProposalsCmd)
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/informalsystems/stakooler/client/display"
+
+	"github.com/spf13/pflag"
+)
+
+// reportFiles returns where report files are written according to the output flags
+func reportFiles() display.ReportFiles {
+	return display.ReportFiles{
+		Dir:      flagOutputDir,
+		Template: flagFileTemplate,
+		Force:    flagForce,
+	}
+}
+
+// report is a report written by a command
+type report struct {
+	name string
+	ext  string
+	// toStdout writes the report to stdout when no output directory is given
+	toStdout bool
+	write    func(w io.Writer) error
+}
+
+// writeReport writes a report to stdout when toStdout is set and no output directory is given,
+// otherwise to a report file
+func writeReport(name string, ext string, toStdout bool, write func(w io.Writer) error) error {
+	return writeReports(report{name: name, ext: ext, toStdout: toStdout, write: write})
+}
+
+// writeReports writes the reports to stdout or to report files. Every report file is created before any report
+// is written, so nothing is written when one of the files cannot be created
+func writeReports(reports ...report) error {
+	var stdout, toFiles []report
+	var names []display.Report
+	for _, r := range reports {
+		if r.toStdout && flagOutputDir == "" {
+			stdout = append(stdout, r)
+			continue
+		}
+		toFiles = append(toFiles, r)
+		names = append(names, display.Report{Name: r.name, Ext: r.ext})
+	}
+
+	files, err := reportFiles().CreateAll(names)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range stdout {
+		if err = r.write(os.Stdout); err != nil {
+			display.RemoveFiles(files)
+			return err
+		}
+	}
+	for i, r := range toFiles {
+		if err = r.write(files[i]); err != nil {
+			// the reports already written are kept
+			display.RemoveFiles(files[i:])
+			return errors.New(fmt.Sprintf("write %s: %s", files[i].Name(), err))
+		}
+		if err = files[i].Close(); err != nil {
+			display.RemoveFiles(files[i+1:])
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeOutputFlags accepts --out as an alias of --output-dir
+func normalizeOutputFlags(f *pflag.FlagSet, name string) pflag.NormalizedName {
+	if name == "out" {
+		name = "output-dir"
+	}
+	return pflag.NormalizedName(name)
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/schollz/progressbar/v3"
+)
+
+// newProgressBar returns a progress bar for the given number of iterations, or a silent one
+// when the output should not be cluttered (e.g. csv output)
+func newProgressBar(totalIterations int, enabled bool) *progressbar.ProgressBar {
+	if !enabled {
+		return progressbar.DefaultSilent(int64(totalIterations))
+	}
+
+	return progressbar.NewOptions(totalIterations, progressbar.OptionEnableColorCodes(true), progressbar.OptionShowBytes(false), progressbar.OptionSetWidth(25), progressbar.OptionUseANSICodes(false), progressbar.OptionClearOnFinish(), progressbar.OptionSetPredictTime(false), progressbar.OptionSetTheme(progressbar.Theme{
+		Saucer:        "▪︎[reset]",
+		SaucerHead:    ">[reset]",
+		SaucerPadding: ".",
+		BarStart:      "[",
+		BarEnd:        "]",
+	}))
+}
+
+func finishProgressBar(bar *progressbar.ProgressBar) {
+	if err := bar.Finish(); err != nil {
+		log.Error().Err(err).Msg("failed to finish bar")
+	}
+}
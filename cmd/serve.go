@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/informalsystems/stakooler/client/cosmos/api"
+	"github.com/informalsystems/stakooler/client/cosmos/model"
+	"github.com/informalsystems/stakooler/client/display"
+	"github.com/informalsystems/stakooler/config"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagListen         *string
+	flagInterval       *time.Duration
+	flagServeValidator *bool
+)
+
+// represents the 'serve' command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Exposes accounts and validators as prometheus metrics",
+	Long: `This command periodically fetches the configured accounts, like 'accounts details' does, and the
+validator statistics, and exposes them as prometheus gauges on /metrics. For example:
+
+stakooler serve --listen :9300 --interval 5m`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if *flagInterval <= 0 {
+			log.Fatal().Msg("--interval must be positive")
+		}
+
+		rawAcctData, err := config.ReadAccountData(flagConfigPath, flagProfile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("error reading account data file")
+		}
+
+		httpClient := api.NewHttpClient(flagEndpointConcurrency)
+		// the price configuration is checked upfront, the oracle is then created on every update
+		oracle, err := config.ParsePriceOracle(rawAcctData, httpClient)
+		if err != nil {
+			log.Fatal().Err(err).Msg("error configuring price providers")
+		}
+		// chains are set up once, their endpoints and queriers are reused by every update
+		chains := config.ParseAccountsConfig(rawAcctData, oracle, flagConcurrency, httpClient)
+		defer func() {
+			for _, chain := range chains {
+				_ = chain.Querier.Close()
+			}
+		}()
+
+		collector := display.NewMetricsCollector()
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(collector)
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+		server := &http.Server{Addr: *flagListen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		go func() {
+			log.Info().Msg(fmt.Sprintf("serving metrics on %s/metrics", *flagListen))
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatal().Err(err).Msg("metrics server failed")
+			}
+		}()
+
+		ticker := time.NewTicker(*flagInterval)
+		defer ticker.Stop()
+		for {
+			chains = updateMetrics(collector, chains, rawAcctData, httpClient)
+
+			select {
+			case <-ctx.Done():
+				shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				if err := server.Shutdown(shutdown); err != nil {
+					log.Error().Err(err).Msg("failed shutting down metrics server")
+				}
+				return
+			case <-ticker.C:
+			}
+		}
+	},
+}
+
+// updateMetrics fetches the accounts of the chains, and the validator statistics when enabled, and publishes them
+// to the collector. Balances and prices are not carried over from the previous update, prices are only reused
+// through the price cache file. Configured chains that could not be set up are tried again. It returns the chains
+// the next update starts from
+func updateMetrics(collector *display.MetricsCollector, chains []*model.Chain, rawAcctData *model.RawAccountData, httpClient *http.Client) []*model.Chain {
+	start := time.Now()
+
+	oracle, err := config.ParsePriceOracle(rawAcctData, httpClient)
+	if err != nil {
+		log.Error().Err(err).Msg("error configuring price providers, skipping update")
+		return chains
+	}
+
+	// the collector still serves the chains of the previous update, so they are fetched again as copies
+	fresh := make([]*model.Chain, 0, len(rawAcctData.Chains))
+	for _, chain := range chains {
+		fresh = append(fresh, chain.Fresh(oracle))
+	}
+	if missing := missingChains(rawAcctData, chains); missing != nil {
+		fresh = append(fresh, config.ParseAccountsConfig(missing, oracle, flagConcurrency, httpClient)...)
+	}
+
+	fetchAccountBalances(fresh, snapshot{}, httpClient, false, false)
+
+	validators := &model.ValidatorList{}
+	if *flagServeValidator {
+		validators = fetchValidatorStats(fresh, httpClient, false)
+	}
+
+	if err = oracle.SaveCache(); err != nil {
+		log.Error().Err(err).Msg("failed saving price cache")
+	}
+
+	collector.Update(fresh, validators, oracle.Currencies, time.Since(start))
+	log.Info().Msg(fmt.Sprintf("metrics updated in %s", time.Since(start).Round(time.Millisecond)))
+	return fresh
+}
+
+// missingChains returns the account data restricted to the configured chains missing from chains, nil when
+// every chain is set up
+func missingChains(rawAcctData *model.RawAccountData, chains []*model.Chain) *model.RawAccountData {
+	missing := *rawAcctData
+	missing.Chains = nil
+	for _, chain := range rawAcctData.Chains {
+		if !slices.ContainsFunc(chains, func(c *model.Chain) bool { return c.Id == chain.Id }) {
+			missing.Chains = append(missing.Chains, chain)
+		}
+	}
+
+	if len(missing.Chains) == 0 {
+		return nil
+	}
+	return &missing
+}
+
+func init() {
+	flagListen = serveCmd.Flags().String("listen", ":9300", "address the metrics server listens on")
+	flagInterval = serveCmd.Flags().Duration("interval", 5*time.Minute, "time between two updates of the metrics")
+	flagServeValidator = serveCmd.Flags().Bool("validators", true, "also expose the validator statistics of the configured accounts")
+	rootCmd.AddCommand(serveCmd)
+}
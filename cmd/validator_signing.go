@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/informalsystems/stakooler/client/cosmos/api"
+	"github.com/informalsystems/stakooler/client/cosmos/model"
+	"github.com/informalsystems/stakooler/client/display"
+	"github.com/informalsystems/stakooler/config"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagSigningOutput *string
+	flagSigningBlocks *int64
+	flagStreaks       *bool
+)
+
+// represents the 'validator signing' command
+var validatorSigningCmd = &cobra.Command{
+	Use:   "signing",
+	Short: "Shows the blocks signed, missed and proposed by the validators",
+	Long: `This command scans the commit signatures of the last blocks of every chain and shows, for the configured
+accounts that are validators, the blocks they signed, missed and proposed. For example:
+
+stakooler validator signing --blocks 1000 -o csv --streaks
+
+Unlike the missed blocks counter of 'validator uptime', every run of consecutive missed blocks is listed with
+its heights and times, e.g. to correlate them with node incidents`,
+	Run: func(cmd *cobra.Command, args []string) {
+		output := *flagSigningOutput
+		if output != outputTable && output != outputCsv {
+			log.Fatal().Msg(fmt.Sprintf("unknown output format %s, use table or csv", output))
+		}
+		if *flagSigningBlocks < 1 {
+			log.Fatal().Msg("--blocks must be greater than 0")
+		}
+
+		rawAcctData, err := config.ReadAccountData(flagConfigPath, flagProfile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("error reading account data file")
+		}
+
+		httpClient := api.NewHttpClient(flagEndpointConcurrency)
+		chains := config.ParseAccountsConfig(rawAcctData, nil, flagConcurrency, httpClient)
+
+		histories := fetchSigningHistory(chains, *flagSigningBlocks, httpClient, output == outputTable)
+
+		switch {
+		case output == outputCsv && *flagStreaks:
+			err = writeReport("validator_miss_streaks", "csv", true, func(w io.Writer) error {
+				return display.WriteMissStreaksCSV(w, histories)
+			})
+		case output == outputCsv:
+			err = writeReport("validator_signing", "csv", true, func(w io.Writer) error {
+				return display.WriteSigningCSV(w, histories)
+			})
+		default:
+			display.PrintSigningTable(histories)
+		}
+
+		if err != nil {
+			log.Fatal().Err(err).Msg("error writing report")
+		}
+	},
+}
+
+// fetchSigningHistory scans the last blocks of every chain, one chain after the other so the blocks of a chain
+// are fetched using the whole worker pool, keeping the validators in the same order as the chains
+func fetchSigningHistory(chains []*model.Chain, blocks int64, httpClient *http.Client, barEnabled bool) []*model.SigningHistory {
+	// iterations are the number of chains
+	bar := newProgressBar(len(chains), barEnabled)
+
+	var histories []*model.SigningHistory
+	for _, chain := range chains {
+		if barEnabled {
+			bar.Describe(fmt.Sprintf("Scanning %d blocks of %s", blocks, chain.Id))
+		}
+
+		chainHistories, err := chain.FetchSigningHistory(blocks, flagConcurrency, httpClient)
+		if err != nil {
+			log.Error().Err(err).Msg(fmt.Sprintf("failed scanning the blocks of %s", chain.Name))
+		}
+		histories = append(histories, chainHistories...)
+		bar.Add(1)
+	}
+	finishProgressBar(bar)
+	return histories
+}
+
+func init() {
+	flagSigningOutput = validatorSigningCmd.Flags().StringP("output", "o", outputTable, "output format: table or csv")
+	flagSigningBlocks = validatorSigningCmd.Flags().Int64("blocks", 100, "number of most recent heights to scan")
+	flagStreaks = validatorSigningCmd.Flags().Bool("streaks", false, "write the runs of consecutive missed blocks instead of the totals in the csv output")
+	validatorCmd.AddCommand(validatorSigningCmd)
+}
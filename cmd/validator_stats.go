@@ -1,6 +1,18 @@
 package cmd
 
 import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/informalsystems/stakooler/client/cosmos/api"
+	"github.com/informalsystems/stakooler/client/cosmos/model"
+	"github.com/informalsystems/stakooler/client/display"
+	"github.com/informalsystems/stakooler/client/pool"
+	"github.com/informalsystems/stakooler/client/zabbix"
+	"github.com/informalsystems/stakooler/config"
+
+	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
 
@@ -9,7 +21,7 @@ var (
 	flagZbxValidatorStats *bool
 )
 
-// represents the 'accounts details' command
+// represents the 'validator stats' command
 var validatorStatsCmd = &cobra.Command{
 	Use:   "stats",
 	Short: "Shows detailed information about a validator statistics",
@@ -17,107 +29,77 @@ var validatorStatsCmd = &cobra.Command{
 
 It shows the validator's voting power, voting power percentage, ranking, number of delegators per chain`,
 	Run: func(cmd *cobra.Command, args []string) {
-		/*
-					barEnabled := !*flagCsvValidatorStats && !*flagZbxValidatorStats
-					config, err := config.LoadConfig(flagConfigPath)
-					if err != nil {
-						log.Fatal().Err(err).Msg("error reading configuration file")
-						os.Exit(1)
-					}
-
-					if *flagZbxValidatorStats {
-						if config.Zabbix.Port <= 0 || config.Zabbix.Port > 65535 || config.Zabbix.Host == "" {
-							log.Fatal().Err(err).Msg("zabbix output requested. missing or incorrect zabbix configuration")
-							os.Exit(1)
-						}
-					}
-					var bar *progressbar.ProgressBar
+		barEnabled := !*flagCsvValidatorStats && !*flagZbxValidatorStats
+		rawAcctData, err := config.ReadAccountData(flagConfigPath, flagProfile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("error reading account data file")
+		}
 
-			/*		if barEnabled {
-						// Progress bar
-						// iterations are the api calls number times the number of accounts
-						totalIterations := len(config.Validators.Entries) * 5 // two API calls
-						bar = progressbar.NewOptions(totalIterations,
-							progressbar.OptionEnableColorCodes(true),
-							progressbar.OptionShowBytes(false),
-							progressbar.OptionSetWidth(25),
-							progressbar.OptionUseANSICodes(false),
-							progressbar.OptionClearOnFinish(),
-							progressbar.OptionSetPredictTime(false),
-							progressbar.OptionSetTheme(progressbar.Theme{
-								Saucer:        "▪︎[reset]",
-								SaucerHead:    ">[reset]",
-								SaucerPadding: ".",
-								BarStart:      "[",
-								BarEnd:        "]",
-							}))
-					} else {
-						bar = progressbar.New(0)
-					}
+		var sender *zabbix.Sender
+		if *flagZbxValidatorStats {
+			if sender, err = config.ParseZabbixConfig(rawAcctData); err != nil {
+				log.Fatal().Err(err).Msg("zabbix output requested, missing or incorrect zabbix configuration")
+			}
+		}
 
-					httpClient := api.NewHttpClient()
+		httpClient := api.NewHttpClient(flagEndpointConcurrency)
+		oracle, err := config.ParsePriceOracle(rawAcctData, httpClient)
+		if err != nil {
+			log.Fatal().Err(err).Msg("error configuring price providers")
+		}
+		chains := config.ParseAccountsConfig(rawAcctData, oracle, flagConcurrency, httpClient)
 
-					// Load each account details
-					for _, validator := range config.Validators.Entries {
-						// Don't show this if csv option enabled
-						if barEnabled {
-							bar.Describe(fmt.Sprintf("Getting statistics for %s", validator.ValoperAddress))
-						}
+		validators := fetchValidatorStats(chains, httpClient, barEnabled)
 
-						// Load assets list
-						// Get Assets list for the chain
-						assets, err := api.QueryAssetList(validator.Chain.Id, httpClient)
-						if err != nil {
-							log.Fatal().Err(err).Str("chain", validator.Chain.Id).Msg("cannot retrieve assets list")
-							os.Exit(1)
-						}
+		if sender != nil {
+			if err = display.ZbxSendChainDiscovery(sender, chains); err == nil {
+				err = display.ZbxValidatorStats(sender, validators)
+			}
+			if err != nil {
+				log.Fatal().Err(err).Msg("error sending to zabbix")
+			}
+		} else if *flagCsvValidatorStats {
+			err = writeReport("validator_stats", "csv", true, func(w io.Writer) error {
+				return display.WriteValidatorCSV(w, validators)
+			})
+			if err != nil {
+				log.Fatal().Err(err).Msg("error writing report")
+			}
+		} else {
+			display.PrintValidatorStatsTable(validators)
+		}
+	},
+}
 
-						for _, asset := range assets.Assets {
-							denom, err := api.QueryParams(validator.Chain.RestEndpoint, httpClient)
-							if err != nil {
-								log.Fatal().Err(err).Str("chain", validator.Chain.Id).Msg("cannot retrieve staking params")
-								os.Exit(1)
-							}
-							if asset.Base == denom.ParamsResponse.BondDenom {
-								validator.Chain.Denom = asset.Symbol
-								for _, du := range asset.DenomUnits {
-									if du.Denom == asset.Display {
-										validator.Chain.Exponent = du.Exponent
-									}
-								}
-							}
-						}
+// fetchValidatorStats fetches the validator statistics of every chain using a worker pool bounded by the
+// concurrency flag, keeping the validators in the same order as the chains
+func fetchValidatorStats(chains []*model.Chain, httpClient *http.Client, barEnabled bool) *model.ValidatorList {
+	// iterations are the number of chains
+	bar := newProgressBar(len(chains), barEnabled)
 
-						// Load validators
-						err = query.LoadValidatorStats(validator, bar)
-						if err != nil {
-							log.Error().Err(err).Str("validator_addr", validator.ValoperAddress).Msg("error loading validator stats")
-						} else {
-							// Don't show this if csv option enabled
-							if barEnabled {
-								bar.Describe(fmt.Sprintf("Got validator %s statistics", validator.ValoperAddress))
-							}
-						}
-					}
+	stats := make([][]*model.Validator, len(chains))
+	pool.Run(len(chains), flagConcurrency, func(i int) {
+		if barEnabled {
+			bar.Describe(fmt.Sprintf("Getting validator statistics for %s", chains[i].Id))
+		}
 
-					// Hide bar
-					bar.Finish()
+		var err error
+		if stats[i], err = chains[i].FetchValidatorStats(httpClient); err != nil {
+			log.Error().Err(err).Msg(fmt.Sprintf("failed fetching validator statistics for %s", chains[i].Name))
+		}
+		bar.Add(1)
+	})
+	finishProgressBar(bar)
 
-					// If csv flag specified use csv output
-					if *flagCsvValidatorStats {
-						// write csv file
-						display.WriteValidatorCSV(&config.Validators)
-					} else if *flagZbxValidatorStats {
-						display.ZbxSendChainDiscovery(&config)
-						display.ZbxValidatorStats(&config)
-					} else {
-						// Print table information
-						display.PrintValidatorStasTable(&config.Validators)
-					}*/
-	},
+	validators := &model.ValidatorList{}
+	for _, chainStats := range stats {
+		validators.Entries = append(validators.Entries, chainStats...)
+	}
+	return validators
 }
 
 func init() {
 	flagCsvValidatorStats = validatorStatsCmd.Flags().BoolP("csv", "c", false, "output the result to a csv format")
+	flagZbxValidatorStats = validatorStatsCmd.Flags().BoolP("zabbix", "z", false, "send the result to the zabbix trapper configured in the account data file")
 	validatorCmd.AddCommand(validatorStatsCmd)
 }
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/informalsystems/stakooler/client/cosmos/api"
+	"github.com/informalsystems/stakooler/client/cosmos/model"
+	"github.com/informalsystems/stakooler/client/display"
+	"github.com/informalsystems/stakooler/client/pool"
+	"github.com/informalsystems/stakooler/config"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var flagUptimeOutput *string
+
+// represents the 'validator uptime' command
+var validatorUptimeCmd = &cobra.Command{
+	Use:   "uptime",
+	Short: "Shows the missed blocks and jail status of the validators",
+	Long: `This command shows the liveness of the configured accounts that are validators. For example:
+
+It shows the blocks missed in the signed blocks window of the slashing module, how many blocks can be missed
+before being jailed, the uptime over the window and whether the validator is jailed or tombstoned`,
+	Run: func(cmd *cobra.Command, args []string) {
+		output := *flagUptimeOutput
+		if output != outputTable && output != outputCsv {
+			log.Fatal().Msg(fmt.Sprintf("unknown output format %s, use table or csv", output))
+		}
+
+		rawAcctData, err := config.ReadAccountData(flagConfigPath, flagProfile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("error reading account data file")
+		}
+
+		httpClient := api.NewHttpClient(flagEndpointConcurrency)
+		chains := config.ParseAccountsConfig(rawAcctData, nil, flagConcurrency, httpClient)
+
+		uptimes := fetchValidatorUptime(chains, httpClient, output == outputTable)
+
+		if output == outputCsv {
+			err = writeReport("validator_uptime", "csv", true, func(w io.Writer) error {
+				return display.WriteUptimeCSV(w, uptimes)
+			})
+			if err != nil {
+				log.Fatal().Err(err).Msg("error writing report")
+			}
+		} else {
+			display.PrintUptimeTable(uptimes)
+		}
+	},
+}
+
+// fetchValidatorUptime fetches the liveness of the validators of every chain using a worker pool bounded by the
+// concurrency flag, keeping the validators in the same order as the chains
+func fetchValidatorUptime(chains []*model.Chain, httpClient *http.Client, barEnabled bool) []*model.Uptime {
+	// iterations are the number of chains
+	bar := newProgressBar(len(chains), barEnabled)
+
+	uptimes := make([][]*model.Uptime, len(chains))
+	pool.Run(len(chains), flagConcurrency, func(i int) {
+		if barEnabled {
+			bar.Describe(fmt.Sprintf("Getting validator uptime for %s", chains[i].Id))
+		}
+
+		var err error
+		if uptimes[i], err = chains[i].FetchValidatorUptime(httpClient); err != nil {
+			log.Error().Err(err).Msg(fmt.Sprintf("failed fetching validator uptime for %s", chains[i].Name))
+		}
+		bar.Add(1)
+	})
+	finishProgressBar(bar)
+
+	var all []*model.Uptime
+	for _, chainUptimes := range uptimes {
+		all = append(all, chainUptimes...)
+	}
+	return all
+}
+
+func init() {
+	flagUptimeOutput = validatorUptimeCmd.Flags().StringP("output", "o", outputTable, "output format: table or csv")
+	validatorCmd.AddCommand(validatorUptimeCmd)
+}
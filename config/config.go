@@ -2,43 +2,65 @@ package config
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"net/http"
+	"os"
 	"path/filepath"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/cosmos/cosmos-sdk/types/bech32"
 	"github.com/informalsystems/stakooler/client/cosmos/api"
 	"github.com/informalsystems/stakooler/client/cosmos/model"
+	"github.com/informalsystems/stakooler/client/pool"
+	"github.com/informalsystems/stakooler/client/zabbix"
 
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
 )
 
-func ReadAccountData(path string) (*model.RawAccountData, error) {
+const (
+	// DefaultProfile is the account file name used when no profile is provided
+	DefaultProfile = "accounts"
+	configDir      = ".stakooler"
+)
+
+// transports a chain can be queried over
+const (
+	transportRest = "rest"
+	transportGrpc = "grpc"
+)
+
+// supported account file formats, in order of precedence
+var configExtensions = []string{"json", "yaml", "yml", "toml"}
+
+// ReadAccountData reads the account data file. An explicit path takes precedence, otherwise the
+// profile (or the default profile) is searched for in ~/.stakooler and the current directory
+func ReadAccountData(path string, profile string) (*model.RawAccountData, error) {
 	var rawAcctData model.RawAccountData
-	if path != "" {
-		cleanPath := filepath.Join(path)
-		fileName := filepath.Base(cleanPath)
-		ext := filepath.Ext(fileName)
-		trimmedName := strings.TrimSuffix(fileName, ext)
-		filePath := filepath.Dir(cleanPath)
-		viper.SetConfigName(trimmedName)
-		viper.SetConfigType(strings.Replace(ext, ".", "", 1))
-		viper.AddConfigPath(filePath)
-	} else {
-		viper.SetConfigName("accounts")
-		viper.SetConfigType("json")
-		viper.AddConfigPath("$HOME/.stakooler")
-		viper.AddConfigPath(".")
+
+	candidates := AccountFileCandidates(path, profile)
+	filePath := ""
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			filePath = candidate
+			break
+		}
+	}
+
+	if filePath == "" {
+		return nil, errors.New(fmt.Sprintf("no account data file found, searched: %s", strings.Join(candidates, ", ")))
 	}
 
-	if err := viper.ReadInConfig(); err != nil {
-		log.Error().Err(err).Msg("error reading file")
+	v := viper.New()
+	v.SetConfigFile(filePath)
+	if err := v.ReadInConfig(); err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("error reading file %s", filePath))
 		return nil, err
 	} else {
-		if err = viper.Unmarshal(&rawAcctData); err != nil {
+		if err = v.Unmarshal(&rawAcctData); err != nil {
 			log.Error().Err(err).Msg("cannot unmarshall account data file")
 			return nil, err
 		}
@@ -46,29 +68,223 @@ func ReadAccountData(path string) (*model.RawAccountData, error) {
 	return &rawAcctData, nil
 }
 
-func ParseAccountsConfig(data *model.RawAccountData, httpClient *http.Client) []*model.Chain {
-	var chains []*model.Chain
-	for _, chain := range data.Chains {
+// AccountFileCandidates returns the list of files that are searched for the account data
+func AccountFileCandidates(path string, profile string) []string {
+	if path != "" {
+		return []string{filepath.Clean(path)}
+	}
+
+	if profile == "" {
+		profile = DefaultProfile
+	}
+
+	var dirs []string
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, configDir))
+	}
+	dirs = append(dirs, ".")
+
+	var candidates []string
+	for _, dir := range dirs {
+		for _, ext := range configExtensions {
+			candidates = append(candidates, filepath.Join(dir, profile+"."+ext))
+		}
+	}
+	return candidates
+}
+
+// default price providers, in the order they are queried, when none are configured
+var defaultPriceProviders = []string{"coinapi", "coingecko"}
+
+// default quote currencies when none are configured
+var defaultCurrencies = []string{"USD", "CAD"}
+
+// default lifetime of persisted prices
+const defaultPriceCacheTTL = time.Hour
+
+// ParsePriceOracle builds the price oracle from the configured price providers, currencies and cache
+func ParsePriceOracle(data *model.RawAccountData, httpClient *http.Client) (*api.PriceOracle, error) {
+	names := data.Prices.Providers
+	if len(names) == 0 {
+		names = defaultPriceProviders
+	}
+
+	currencies := data.Prices.Currencies
+	if len(currencies) == 0 {
+		currencies = defaultCurrencies
+	}
+
+	var quotes []string
+	for _, currency := range currencies {
+		currency = strings.ToUpper(strings.TrimSpace(currency))
+		if currency == "" {
+			return nil, errors.New("empty currency in prices.currencies")
+		}
+		if !slices.Contains(quotes, currency) {
+			quotes = append(quotes, currency)
+		}
+	}
+
+	var providers []api.PriceProvider
+	for _, name := range names {
+		switch strings.ToLower(name) {
+		case "coinapi":
+			providers = append(providers, &api.CoinApiProvider{Client: httpClient})
+		case "coingecko":
+			providers = append(providers, &api.CoinGeckoProvider{Client: httpClient})
+		case "file":
+			if data.Prices.File == "" {
+				return nil, errors.New("price provider file requires prices.file to be set")
+			}
+			provider, err := api.NewPriceFileProvider(expandHome(data.Prices.File))
+			if err != nil {
+				return nil, err
+			}
+			providers = append(providers, provider)
+		default:
+			return nil, errors.New(fmt.Sprintf("unknown price provider %s", name))
+		}
+	}
+
+	ttl := defaultPriceCacheTTL
+	if data.Prices.Cache.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(data.Prices.Cache.TTL); err != nil {
+			return nil, errors.New(fmt.Sprintf("invalid prices.cache.ttl: %s", err))
+		}
+	}
+
+	return api.NewPriceOracle(providers, quotes, expandHome(data.Prices.Cache.File), ttl)
+}
+
+// ParseZabbixConfig returns the sender for the configured Zabbix trapper
+func ParseZabbixConfig(data *model.RawAccountData) (*zabbix.Sender, error) {
+	cfg := data.Zabbix
+	if cfg.Server == "" || cfg.Host == "" {
+		return nil, errors.New("zabbix.server and zabbix.host must be set")
+	}
+
+	port := cfg.Port
+	if port == 0 {
+		port = zabbix.DefaultPort
+	}
+	if port < 0 || port > 65535 {
+		return nil, errors.New(fmt.Sprintf("invalid zabbix.port %d", cfg.Port))
+	}
+
+	return zabbix.NewSender(cfg.Server, port, cfg.Host), nil
+}
+
+// denomFilter combines the global and chain filters: the chain includes replace the global ones
+// while excludes of both apply
+func denomFilter(global model.RawDenomFilter, chain model.RawDenomFilter) model.DenomFilter {
+	include := global.Include
+	if len(chain.Include) > 0 {
+		include = chain.Include
+	}
+	return model.NewDenomFilter(include, append(slices.Clone(global.Exclude), chain.Exclude...))
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
+
+// ParseAccountsConfig builds the chains and their accounts from the account data, querying up to workers
+// chains at the same time. The chains are returned in the order they appear in the account data
+func ParseAccountsConfig(data *model.RawAccountData, oracle *api.PriceOracle, workers int, httpClient *http.Client) []*model.Chain {
+	directory := api.NewAssetDirectory(httpClient)
+
+	parsed := make([]*model.Chain, len(data.Chains))
+	pool.Run(len(data.Chains), workers, func(i int) {
+		chain := data.Chains[i]
 		chainData := &model.Chain{
-			Name:         chain.Name,
-			Id:           chain.Id,
-			RestEndpoint: chain.Rest,
-			AssetList:    &api.AssetList{},
+			Name:        chain.Name,
+			Id:          chain.Id,
+			AssetList:   &api.AssetList{},
+			PriceOracle: oracle,
+			Directory:   directory,
+			DenomFilter: denomFilter(data.Denoms, chain.Denoms),
+		}
+
+		// the chain registry is queried at most once per chain, also for the bech32 prefix
+		var chainDataRegistry *api.ChainData
+		registryData := func() (*api.ChainData, error) {
+			if chainDataRegistry != nil {
+				return chainDataRegistry, nil
+			}
+			registry := &api.ChainData{}
+			if err := registry.QueryChainData(chain.Name, httpClient); err != nil {
+				return nil, err
+			}
+			chainDataRegistry = registry
+			return registry, nil
+		}
+
+		urls := append([]string{chain.Rest}, chain.RestEndpoints...)
+		if chain.RegistryRest {
+			if registry, err := registryData(); err != nil {
+				log.Error().Err(err).Msg(fmt.Sprintf("query chain registry rest endpoints: %s", chain.Id))
+			} else {
+				for _, rest := range registry.Apis.Rest {
+					urls = append(urls, rest.Address)
+				}
+			}
+		}
+
+		transport := strings.ToLower(chain.Transport)
+		endpoints := api.RegisterEndpoints(urls)
+		// the REST endpoints of gRPC chains are only needed to trace IBC denoms and for validator stats
+		if len(endpoints.URLs()) == 0 && transport != transportGrpc {
+			log.Error().Msg(fmt.Sprintf("no rest endpoint, skipping chain: %s", chain.Id))
+			return
+		}
+		for _, health := range endpoints.CheckHealth(httpClient) {
+			if !health.Healthy() {
+				log.Warn().Err(health.Err).Msg(fmt.Sprintf("rest endpoint %s of %s is unhealthy", health.Url, chain.Id))
+			}
+		}
+		chainData.RestEndpoint = endpoints.Preferred()
+
+		switch transport {
+		case "", transportRest:
+			chainData.Querier = api.NewRestQuerier(chainData.RestEndpoint, httpClient)
+		case transportGrpc:
+			if chain.Grpc == "" {
+				log.Error().Msg(fmt.Sprintf("no grpc endpoint, skipping chain: %s", chain.Id))
+				return
+			}
+			querier, err := api.NewGrpcQuerier(chain.Grpc, chain.GrpcTLS)
+			if err != nil {
+				log.Error().Err(err).Msg(fmt.Sprintf("skipping chain: %s", chain.Id))
+				return
+			}
+			chainData.Querier = querier
+		default:
+			log.Error().Msg(fmt.Sprintf("unknown transport %s, skipping chain: %s", chain.Transport, chain.Id))
+			return
 		}
 
 		if err := chainData.AssetList.QueryAssetList(chain.Name, httpClient); err != nil {
 			log.Error().Err(err).Msg(fmt.Sprintf("query asset list: %s", chain.Id))
+		} else {
+			directory.Add(chain.Id, chainData.AssetList)
 		}
 
 		prefixResponse := api.Bech32PrefixResponse{}
-		if err := prefixResponse.GetPrefix(chainData.RestEndpoint, httpClient); err != nil {
+		if err := chainData.Querier.Bech32Prefix(&prefixResponse); err != nil {
 			log.Error().Err(err).Msg(fmt.Sprintf("query chain prefix, trying asset list %s", chainData.Id))
-			chainDataRegistry := api.ChainData{}
-			if err = chainDataRegistry.QueryChainData(chain.Name, httpClient); err != nil {
+			if registry, err := registryData(); err != nil {
 				log.Error().Err(err).Msg(fmt.Sprintf("query chain data, skipping chain: %s", chainData.Id))
-				continue
+				_ = chainData.Querier.Close()
+				return
 			} else {
-				chainData.Bech32Prefix = chainDataRegistry.Bech32Prefix
+				chainData.Bech32Prefix = registry.Bech32Prefix
 			}
 		} else if chain.Name == "panacea" {
 			// mediblock incorrectly shows cosmos as the prefix, so here we are, setting it manually
@@ -78,7 +294,7 @@ func ParseAccountsConfig(data *model.RawAccountData, httpClient *http.Client) []
 		}
 
 		params := &api.StakingParamsResponse{}
-		if err := params.QueryParams(chainData.RestEndpoint, httpClient); err != nil {
+		if err := chainData.Querier.StakingParams(params); err != nil {
 			log.Error().Err(err).Msg(fmt.Sprintf("query staking paramas, skipping chain: %s", chainData.Id))
 		} else {
 			chainData.BondDenom = params.ParamsResponse.BondDenom
@@ -110,10 +326,18 @@ func ParseAccountsConfig(data *model.RawAccountData, httpClient *http.Client) []
 					Address: encodedAddr,
 					Valoper: encodeValoper,
 					Tokens:  make(map[string]*model.Token),
+					Totals:  make(map[string]float64),
 				})
 			}
 		}
-		chains = append(chains, chainData)
+		parsed[i] = chainData
+	})
+
+	var chains []*model.Chain
+	for _, chainData := range parsed {
+		if chainData != nil {
+			chains = append(chains, chainData)
+		}
 	}
 	return chains
 }